@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// configFileName is the name of the configuration file looked up in the current
+// directory and in the user configuration directory.
+const configFileName = "csv2excel.yaml"
+
+var (
+	configFile  string
+	profileName string
+)
+
+// config is the content of a configuration file. Keys are flag names, and values
+// set the flag unless it is given on the command line. Profiles hold named sets of
+// values that are applied on top of the top-level values when selected with --profile.
+type config struct {
+	Values   map[string]any            `yaml:",inline"`
+	Profiles map[string]map[string]any `yaml:"profiles"`
+}
+
+// configPaths returns the configuration files to load, in order of increasing precedence.
+func configPaths() []string {
+	if configFile != "" {
+		return []string{configFile}
+	}
+	var paths []string
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			configHome = filepath.Join(home, ".config")
+		}
+	}
+	if configHome != "" {
+		paths = append(paths, filepath.Join(configHome, "csv2excel", configFileName))
+	}
+	return append(paths, configFileName)
+}
+
+// loadConfig reads the configuration files and returns the flag values to apply, with the
+// values of the selected profile taking precedence over top-level values, and project-local
+// files taking precedence over the user configuration.
+func loadConfig(paths []string, profile string) (map[string]any, error) {
+	values := make(map[string]any)
+	profileValues := make(map[string]any)
+	profileFound := false
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) && configFile == "" {
+			continue
+		}
+		if err != nil {
+			return nil, readError(fmt.Errorf("failed to read config file: %w", err))
+		}
+		var c config
+		if err := yaml.Unmarshal(data, &c); err != nil {
+			return nil, usageError("invalid config file %s: %w", path, err)
+		}
+		for key, value := range c.Values {
+			values[key] = value
+		}
+		if p, ok := c.Profiles[profile]; ok && profile != "" {
+			profileFound = true
+			for key, value := range p {
+				profileValues[key] = value
+			}
+		}
+	}
+	if profile != "" && !profileFound {
+		return nil, usageError("profile %s not found in config files %v", profile, paths)
+	}
+	for key, value := range profileValues {
+		values[key] = value
+	}
+	return values, nil
+}
+
+// mutuallyExclusiveAnnotation is the annotation cobra sets on flags marked as mutually exclusive,
+// with one space-separated list of flag names per group.
+const mutuallyExclusiveAnnotation = "cobra_annotation_mutually_exclusive"
+
+// applyConfig sets the flags of the command from the configuration values, unless the flag, or
+// a flag it is mutually exclusive with, has been given on the command line. Values for flags the
+// command does not have are ignored, so a single configuration can be shared between commands.
+func applyConfig(flags *pflag.FlagSet, values map[string]any) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		flag := flags.Lookup(key)
+		if flag == nil || flag.Changed || excludedByCommandLine(flags, flag) {
+			continue
+		}
+		// Lists are passed item by item to list flags, so items may contain commas.
+		if items, ok := values[key].([]any); ok {
+			if slice, ok := flag.Value.(pflag.SliceValue); ok {
+				list := make([]string, len(items))
+				for i, item := range items {
+					list[i] = configValue(item)
+				}
+				if err := slice.Replace(list); err != nil {
+					return usageError("invalid config value for %s: %w", key, err)
+				}
+				continue
+			}
+		}
+		if err := flag.Value.Set(configValue(values[key])); err != nil {
+			return usageError("invalid config value for %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// excludedByCommandLine reports whether a flag that is mutually exclusive with the flag
+// has been given on the command line.
+func excludedByCommandLine(flags *pflag.FlagSet, flag *pflag.Flag) bool {
+	for _, group := range flag.Annotations[mutuallyExclusiveAnnotation] {
+		for _, name := range strings.Fields(group) {
+			if other := flags.Lookup(name); other != nil && other.Changed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// configValue converts a configuration value to its flag representation.
+// Lists are joined with commas and maps are written as key=value pairs.
+func configValue(value any) string {
+	switch v := value.(type) {
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = configValue(item)
+		}
+		return strings.Join(items, ",")
+	case map[string]any:
+		pairs := make([]string, 0, len(v))
+		for key, item := range v {
+			pairs = append(pairs, key+"="+configValue(item))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// loadConfigFlags is run before every command to apply the configuration files to its flags.
+func loadConfigFlags(cmd *cobra.Command, args []string) error {
+	values, err := loadConfig(configPaths(), profileName)
+	if err != nil {
+		return err
+	}
+	return applyConfig(cmd.Flags(), values)
+}
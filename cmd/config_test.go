@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), configFileName)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func Test_loadConfig(t *testing.T) {
+	user := writeConfig(t, `
+delimiter: ";"
+convert: true
+profiles:
+  vendorX:
+    delimiter: "|"
+    columns: [id, amount]
+`)
+	local := writeConfig(t, `
+convert: false
+profiles:
+  vendorX:
+    rename:
+      amount: Amount
+`)
+	tests := []struct {
+		name     string
+		profile  string
+		expected map[string]any
+		wantErr  bool
+	}{
+		{
+			name:     "Top-level values with local precedence",
+			expected: map[string]any{"delimiter": ";", "convert": false},
+		},
+		{
+			name:    "Profile values override top-level values",
+			profile: "vendorX",
+			expected: map[string]any{
+				"delimiter": "|",
+				"convert":   false,
+				"columns":   []any{"id", "amount"},
+				"rename":    map[string]any{"amount": "Amount"},
+			},
+		},
+		{
+			name:    "Unknown profile",
+			profile: "vendorY",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadConfig([]string{user, local, filepath.Join(t.TempDir(), "missing.yaml")}, tt.profile)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("loadConfig() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_applyConfig(t *testing.T) {
+	var (
+		delim    string
+		convert  bool
+		columns  []string
+		rename   map[string]string
+		formulas []string
+	)
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringVar(&delim, "delimiter", ",", "")
+	flags.BoolVar(&convert, "convert", false, "")
+	flags.StringSliceVar(&columns, "columns", []string{}, "")
+	flags.StringToStringVar(&rename, "rename", map[string]string{}, "")
+	flags.StringArrayVar(&formulas, "formula", []string{}, "")
+	if err := flags.Parse([]string{"--delimiter", "\t"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := applyConfig(flags, map[string]any{
+		"delimiter": ";",
+		"convert":   true,
+		"columns":   []any{"id", 2},
+		"rename":    map[string]any{"a": "b", "c": "d"},
+		"formula":   []any{"Total=ROUND([Qty]*[Price],2)"},
+		"files":     []any{"ignored.csv"},
+	})
+	if err != nil {
+		t.Fatalf("applyConfig() error = %v", err)
+	}
+	if delim != "\t" {
+		t.Errorf("applyConfig() overrode the command line delimiter with %q", delim)
+	}
+	if !convert {
+		t.Errorf("applyConfig() did not set convert")
+	}
+	if !reflect.DeepEqual(columns, []string{"id", "2"}) {
+		t.Errorf("applyConfig() columns = %v", columns)
+	}
+	if !reflect.DeepEqual(rename, map[string]string{"a": "b", "c": "d"}) {
+		t.Errorf("applyConfig() rename = %v", rename)
+	}
+	if !reflect.DeepEqual(formulas, []string{"Total=ROUND([Qty]*[Price],2)"}) {
+		t.Errorf("applyConfig() formulas = %v", formulas)
+	}
+
+	if err := applyConfig(flags, map[string]any{"convert": "maybe"}); err == nil {
+		t.Errorf("applyConfig() expected error for invalid value")
+	}
+}
+
+func Test_applyConfig_MutuallyExclusive(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		values  map[string]any
+		skipped string
+	}{
+		{name: "Force and no-clobber", args: []string{"--no-clobber"}, values: map[string]any{"force": true}, skipped: "force"},
+		{name: "Output and name", args: []string{"--name", "cli"}, values: map[string]any{"output": "config.xlsx"}, skipped: "output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test", RunE: func(cmd *cobra.Command, args []string) error { return nil }}
+			cmd.Flags().Bool("force", false, "")
+			cmd.Flags().Bool("no-clobber", false, "")
+			cmd.Flags().String("output", "", "")
+			cmd.Flags().String("name", "", "")
+			cmd.MarkFlagsMutuallyExclusive("force", "no-clobber")
+			cmd.MarkFlagsMutuallyExclusive("output", "name")
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := applyConfig(cmd.Flags(), tt.values); err != nil {
+				t.Fatalf("applyConfig() error = %v", err)
+			}
+			flag := cmd.Flags().Lookup(tt.skipped)
+			if flag.Value.String() != flag.DefValue {
+				t.Errorf("applyConfig() set %s = %v, expected it to be skipped", tt.skipped, flag.Value)
+			}
+		})
+	}
+}
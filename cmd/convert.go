@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/HampB/csv2excel/internal/file"
+	"github.com/spf13/cobra"
+)
+
+// convertCmd represents the convert command
+var (
+	outputDir string
+
+	convertCmd = &cobra.Command{
+		Use:   "convert [files or patterns...]",
+		Short: "Convert many CSV files to their own Excel files",
+		Long: `The convert command converts each of the given CSV files to its own Excel file.
+Files can be given as paths or glob patterns. The Excel files are written next to the CSV files,
+or into the folder given with --out-dir, named after --name if given. Files whose Excel file is newer than the CSV file are skipped. For example:
+
+csv2excel convert data/*.csv
+csv2excel convert 'exports/**/*.csv' --out-dir converted --jobs 4`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if delimiter == "" {
+				return usageError("delimiter cannot be empty")
+			}
+			delimiterRune := []rune(delimiter)[0]
+
+			inputs, err := expandFilePatterns(args)
+			if err != nil {
+				return usageError("%w", err)
+			}
+			if len(inputs) == 0 {
+				return usageError("no files match %v", args)
+			}
+			for _, input := range inputs {
+				if !isCSVFile(input) {
+					return usageError("invalid input file format: %s. Please provide CSV files", input)
+				}
+			}
+			if err := checkNameTemplate(len(inputs)); err != nil {
+				return err
+			}
+			if err := checkOutputCollisions(inputs); err != nil {
+				return err
+			}
+			if outputDir != "" {
+				if err := prepareOutputDir(outputDir); err != nil {
+					return err
+				}
+			}
+
+			if dryRun {
+				// Previews are printed as files are converted, so convert one file at a time.
+				jobs = 1
+			}
+			results := convertFiles(inputs, delimiterRune, jobs)
+			return printConvertSummary(results)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+
+	addOutputFlags(convertCmd)
+	convertCmd.Flags().IntVarP(&jobs, "jobs", "j", runtime.NumCPU(), "Maximum number of files to convert concurrently")
+	convertCmd.Flags().StringVarP(&delimiter, "delimiter", "d", ",", "Delimiter for CSV file")
+	convertCmd.Flags().BoolVarP(&convertTypes, "convert", "c", false, "Convert column types to inferred types")
+	addTransformFlags(convertCmd)
+	addExcelFlags(convertCmd)
+	addDryRunFlags(convertCmd)
+	convertCmd.Flags().BoolVar(&noClobber, "no-clobber", false, "Never overwrite existing Excel files; by default Excel files older than their CSV file are overwritten without asking")
+}
+
+// convertFile reads the CSV file at inputPath, applies the column, type and row options given
+// on the command line and saves the result as an Excel file at outputPath, or prints a preview with --dry-run.
+// Returns the converted CSV, or an error classified by its exit code.
+func convertFile(inputPath, outputPath string, delimiter rune) (*file.CSV, error) {
+	f := file.New(
+		file.WithFilePath(inputPath),
+		file.WithDelimiter(delimiter),
+	)
+	if err := f.Read(); err != nil {
+		return nil, readError(err)
+	}
+	if err := applyColumnOptions(f); err != nil {
+		return nil, usageError("%w", err)
+	}
+	if convertTypes || needsColumnTypes() {
+		f.InferColumnTypes()
+		f.ConvertColumnTypes()
+	}
+	if err := applyRowOptions(f); err != nil {
+		return nil, usageError("%w", err)
+	}
+	options, err := excelOptions(f)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepareOutputDir(filepath.Dir(outputPath)); err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return f, printPreview(os.Stdout, f, outputPath, previewRows)
+	}
+	if err := f.SaveAsExcel(outputPath, "Sheet1", options...); err != nil {
+		return nil, writeError(err)
+	}
+	return f, nil
+}
+
+// checkOutputCollisions returns a usage error if two inputs would be written to the same Excel file,
+// e.g. files with the same name in different folders converted into one --out-dir.
+func checkOutputCollisions(inputs []string) error {
+	outputs := make(map[string]string, len(inputs))
+	for _, input := range inputs {
+		output := filepath.Clean(resolveOutputPath(input))
+		if previous, ok := outputs[output]; ok {
+			return usageError("%s and %s would both be written to %s", previous, input, output)
+		}
+		outputs[output] = input
+	}
+	return nil
+}
+
+// convertResult is the outcome of converting a single file in batch mode.
+type convertResult struct {
+	input   string
+	output  string
+	records int
+	skipped bool
+	reason  string
+	err     error
+}
+
+// convertFiles converts each input to its own Excel file, using at most jobs workers.
+// Inputs whose Excel file is newer than the input are skipped, as are inputs whose Excel file
+// exists at all with --no-clobber. The results follow the order of inputs.
+func convertFiles(inputs []string, delimiter rune, jobs int) []convertResult {
+	results := make([]convertResult, len(inputs))
+	slots := make(chan struct{}, max(jobs, 1))
+	wg := sync.WaitGroup{}
+	for i, input := range inputs {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(index int, input string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			result := convertResult{input: input, output: resolveOutputPath(input)}
+			if upToDate(input, result.output) {
+				result.skipped = true
+				result.reason = "is up to date"
+			} else if exists(result.output) && noClobber {
+				result.skipped = true
+				result.reason = "already exists"
+			} else if f, err := convertFile(input, result.output, delimiter); err != nil {
+				result.err = err
+			} else {
+				result.records = len(f.Records)
+			}
+			results[index] = result
+		}(i, input)
+	}
+	wg.Wait()
+	return results
+}
+
+// upToDate reports whether the output exists and is newer than the input.
+func upToDate(input, output string) bool {
+	inputInfo, err := os.Stat(input)
+	if err != nil {
+		return false
+	}
+	outputInfo, err := os.Stat(output)
+	if err != nil {
+		return false
+	}
+	return outputInfo.ModTime().After(inputInfo.ModTime())
+}
+
+// exists reports whether a file exists at the path.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// printConvertSummary prints the outcome of each conversion followed by a summary. Failures are
+// printed to stderr. Returns an error if any conversion failed, with a partial success exit code
+// if other files were converted.
+func printConvertSummary(results []convertResult) error {
+	converted, skipped, failed := 0, 0, 0
+	var firstErr error
+	for _, result := range results {
+		switch {
+		case result.err != nil:
+			failed++
+			if firstErr == nil {
+				firstErr = result.err
+			}
+			fmt.Fprintf(os.Stderr, "Failed to convert %s: %v\n", result.input, result.err)
+		case result.skipped:
+			skipped++
+			fmt.Printf("Skipped %s, %s %s\n", result.input, result.output, result.reason)
+		case dryRun:
+			converted++
+		default:
+			converted++
+			fmt.Printf("Converted %d records from %s to %s\n", result.records, result.input, result.output)
+		}
+	}
+	fmt.Printf("Converted %d files, skipped %d files, %d failed\n", converted, skipped, failed)
+
+	if failed == 0 {
+		return nil
+	}
+	if converted+skipped > 0 {
+		return &exitCodeError{code: exitPartial, err: fmt.Errorf("%d of %d files could not be converted", failed, len(results))}
+	}
+	return firstErr
+}
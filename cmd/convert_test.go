@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_convertFiles(t *testing.T) {
+	dir := t.TempDir()
+	inputs := writeTestFiles(t, dir, 3)
+	missing := filepath.Join(dir, "missing.csv")
+	inputs = append(inputs, missing)
+
+	// The Excel file for the first input is newer than the input, so it is skipped.
+	upToDateOutput := filepath.Join(dir, "file0.xlsx")
+	if err := os.WriteFile(upToDateOutput, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(inputs[0], past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	results := convertFiles(inputs, ',', 2)
+	if len(results) != len(inputs) {
+		t.Fatalf("convertFiles() returned %d results, expected %d", len(results), len(inputs))
+	}
+	for i, result := range results {
+		if result.input != inputs[i] {
+			t.Errorf("convertFiles() result %d is for %s, expected %s", i, result.input, inputs[i])
+		}
+	}
+	if !results[0].skipped {
+		t.Errorf("convertFiles() expected %s to be skipped", inputs[0])
+	}
+	for _, result := range results[1:3] {
+		if result.err != nil || result.skipped || result.records != 1 {
+			t.Errorf("convertFiles() result = %+v, expected 1 converted record", result)
+		}
+		if _, err := os.Stat(result.output); err != nil {
+			t.Errorf("convertFiles() did not write %s: %v", result.output, err)
+		}
+	}
+	if results[3].err == nil || exitCode(results[3].err) != exitInput {
+		t.Errorf("convertFiles() error = %v, expected input error for %s", results[3].err, missing)
+	}
+	if err := printConvertSummary(results); exitCode(err) != exitPartial {
+		t.Errorf("printConvertSummary() error = %v, expected partial success", err)
+	}
+}
+
+func Test_checkOutputCollisions(t *testing.T) {
+	defer func() { outputDir = "" }()
+	tests := []struct {
+		name    string
+		dir     string
+		inputs  []string
+		wantErr bool
+	}{
+		{name: "In place", inputs: []string{filepath.Join("a", "day.csv"), filepath.Join("b", "day.csv")}},
+		{name: "Same name into output directory", dir: "out", inputs: []string{filepath.Join("a", "day.csv"), filepath.Join("b", "day.csv")}, wantErr: true},
+		{name: "Compressed copy", inputs: []string{"day.csv", "day.csv.gz"}, wantErr: true},
+		{name: "Different names", dir: "out", inputs: []string{"a.csv", "b.csv"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir = tt.dir
+			err := checkOutputCollisions(tt.inputs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkOutputCollisions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && exitCode(err) != exitUsage {
+				t.Errorf("checkOutputCollisions() exit code = %d, expected %d", exitCode(err), exitUsage)
+			}
+		})
+	}
+}
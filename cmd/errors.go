@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Exit codes returned by the commands, so that scripts can tell failures apart.
+const (
+	// exitError is returned for failures that do not fall into any other category.
+	exitError = 1
+	// exitUsage is returned for invalid flags and arguments.
+	exitUsage = 2
+	// exitInput is returned when an input file cannot be found or opened.
+	exitInput = 3
+	// exitParse is returned when an input file cannot be parsed as CSV.
+	exitParse = 4
+	// exitWrite is returned when the output file cannot be written.
+	exitWrite = 5
+	// exitPartial is returned when the output was written, but some input files were skipped.
+	exitPartial = 6
+)
+
+// exitCodeError is an error with the exit code the process should terminate with.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
+// usageError returns an error for invalid flags or arguments.
+func usageError(format string, args ...any) error {
+	return &exitCodeError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
+
+// writeError returns an error for failures writing the output.
+func writeError(err error) error {
+	return &exitCodeError{code: exitWrite, err: err}
+}
+
+// readError returns an error for failures reading the input. It is classified as a parse error
+// if any of the causes is a CSV parse error, and as an input error otherwise.
+func readError(err error, causes ...error) error {
+	code := exitInput
+	var parseErr *csv.ParseError
+	for _, cause := range append(causes, err) {
+		if errors.As(cause, &parseErr) {
+			code = exitParse
+		}
+	}
+	return &exitCodeError{code: code, err: err}
+}
+
+// exitCode returns the exit code for an error returned by a command. Errors that have
+// not been classified come from cobra's flag parsing and are reported as usage errors.
+func exitCode(err error) int {
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitUsage
+}
+
+// validateChoice returns a usage error if the value of the flag is not one of the choices.
+func validateChoice(flag, value string, choices ...string) error {
+	if slices.Contains(choices, value) {
+		return nil
+	}
+	return usageError("invalid value for --%s: %s, expected %s", flag, value, strings.Join(choices, ", "))
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func Test_exitCode(t *testing.T) {
+	parseErr := &csv.ParseError{StartLine: 2, Line: 2, Column: 1, Err: csv.ErrFieldCount}
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "Unclassified error", err: errors.New("unknown flag: --bogus"), expected: exitUsage},
+		{name: "Usage error", err: usageError("invalid value for --%s", "keep"), expected: exitUsage},
+		{name: "Input error", err: readError(os.ErrNotExist), expected: exitInput},
+		{name: "Parse error", err: readError(fmt.Errorf("reading a.csv: %w", parseErr)), expected: exitParse},
+		{name: "Parse error among causes", err: readError(errors.New("encountered errors"), os.ErrNotExist, parseErr), expected: exitParse},
+		{name: "Write error", err: writeError(os.ErrPermission), expected: exitWrite},
+		{name: "Wrapped error", err: fmt.Errorf("merge: %w", writeError(os.ErrPermission)), expected: exitWrite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.expected {
+				t.Errorf("exitCode() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_validateChoice(t *testing.T) {
+	if err := validateChoice("keep", "last", "first", "last"); err != nil {
+		t.Errorf("validateChoice() error = %v", err)
+	}
+	err := validateChoice("keep", "middle", "first", "last")
+	if err == nil || exitCode(err) != exitUsage {
+		t.Errorf("validateChoice() error = %v, expected usage error", err)
+	}
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/HampB/csv2excel/internal/file"
+	"github.com/spf13/cobra"
+)
+
+var (
+	formulaDefinitions []string
+	totalsFunctions    []string
+	summarySheet       bool
+	pivotSpecs         []string
+	chartSpecs         []string
+	conditionalSpecs   []string
+	dropdownColumns    []string
+	dropdownMax        int
+	blankRows          int
+	linkText           map[string]string
+
+	properties file.Properties
+	created    string
+)
+
+// addExcelFlags registers the flags shared by all commands that control the content of the Excel workbook.
+func addExcelFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&formulaDefinitions, "formula", []string{}, `Append a column calculated by an Excel formula, e.g. --formula "Total=[Qty]*[Price]", can be repeated`)
+	cmd.Flags().StringSliceVar(&totalsFunctions, "totals", []string{}, "Append totals rows under the data with these functions for each numeric column: sum, average or count, implies --convert")
+	cmd.Flags().BoolVar(&summarySheet, "summary", false, "Add a Summary sheet with statistics for each column, implies --convert")
+	cmd.Flags().StringArrayVar(&pivotSpecs, "pivot", []string{}, "Add a pivot table on its own sheet, e.g. --pivot rows=region,cols=month,values=sum(amount), can be repeated, implies --convert")
+	cmd.Flags().StringArrayVar(&chartSpecs, "chart", []string{}, "Add a line, bar, column, pie or scatter chart, e.g. --chart type=line,x=date,y=revenue,cost, can be repeated, implies --convert")
+	cmd.Flags().StringArrayVar(&conditionalSpecs, "conditional-format", []string{}, "Format a column by its values: color-scale, data-bar, duplicates, above(n) or below(n), e.g. --conditional-format amount=above(1000), can be repeated, implies --convert")
+	cmd.Flags().StringSliceVar(&dropdownColumns, "dropdowns", []string{}, "Restrict columns to their values with a dropdown list, or auto for all text columns with few distinct values, implies --convert")
+	cmd.Flags().IntVar(&dropdownMax, "dropdown-max", 10, "Maximum number of distinct values of the columns chosen by --dropdowns auto")
+	cmd.Flags().IntVar(&blankRows, "blank-rows", 0, "Number of blank rows for data entry under the data, covered by formulas, formats and dropdowns")
+	cmd.Flags().StringToStringVar(&linkText, "link-text", map[string]string{}, "Show the values of another column as the text of a hyperlink or email column, e.g. --link-text url=title, implies --convert")
+	cmd.Flags().StringVar(&properties.Title, "title", "", "Document title, defaults to the name of the CSV file, or of the Excel file when merging")
+	cmd.Flags().StringVar(&properties.Subject, "subject", "", "Document subject")
+	cmd.Flags().StringVar(&properties.Author, "author", "", "Document author, defaults to csv2excel")
+	cmd.Flags().StringVar(&properties.Company, "company", "", "Document company")
+	cmd.Flags().StringVar(&properties.Keywords, "keywords", "", "Document keywords")
+	cmd.Flags().StringVar(&properties.Description, "description", "", "Document description")
+	cmd.Flags().StringVar(&created, "created", "", "Document creation time, e.g. 2024-01-31 or 2024-01-31T09:00:00Z, defaults to the conversion time")
+}
+
+// parseCreated parses the --created flag as an RFC 3339 timestamp or a date.
+func parseCreated(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid value for --created: %s, expected a date or timestamp", value)
+}
+
+// needsColumnTypes reports whether the workbook options require the column types to be converted.
+func needsColumnTypes() bool {
+	return len(totalsFunctions) > 0 || summarySheet || len(pivotSpecs) > 0 || len(chartSpecs) > 0 || len(conditionalSpecs) > 0 || len(dropdownColumns) > 0 || len(linkText) > 0
+}
+
+// excelOptions returns the workbook options given on the command line, checked against the columns of f.
+func excelOptions(f *file.CSV) ([]file.ExcelOption, error) {
+	var options []file.ExcelOption
+	columns := f.GetHeaderNames()
+	if len(formulaDefinitions) > 0 {
+		formulas := make([]file.Formula, len(formulaDefinitions))
+		for i, definition := range formulaDefinitions {
+			formula, err := file.ParseFormula(definition)
+			if err != nil {
+				return nil, usageError("%w", err)
+			}
+			formulas[i] = formula
+			columns = append(columns, formula.Name)
+		}
+		if err := f.CheckFormulas(formulas...); err != nil {
+			return nil, usageError("%w", err)
+		}
+		options = append(options, file.WithFormulas(formulas...))
+	}
+	for _, function := range totalsFunctions {
+		if err := validateChoice("totals", function, "sum", "average", "count"); err != nil {
+			return nil, err
+		}
+	}
+	if len(totalsFunctions) > 0 {
+		options = append(options, file.WithTotals(totalsFunctions...))
+	}
+	if len(pivotSpecs) > 0 {
+		pivots := make([]file.Pivot, len(pivotSpecs))
+		for i, spec := range pivotSpecs {
+			pivot, err := file.ParsePivot(spec)
+			if err != nil {
+				return nil, usageError("%w", err)
+			}
+			if err := pivot.Check(columns); err != nil {
+				return nil, usageError("%w", err)
+			}
+			pivots[i] = pivot
+		}
+		options = append(options, file.WithPivots(pivots...))
+	}
+	if len(chartSpecs) > 0 {
+		charts := make([]file.Chart, len(chartSpecs))
+		for i, spec := range chartSpecs {
+			chart, err := file.ParseChart(spec)
+			if err != nil {
+				return nil, usageError("%w", err)
+			}
+			if err := chart.Check(columns); err != nil {
+				return nil, usageError("%w", err)
+			}
+			charts[i] = chart
+		}
+		options = append(options, file.WithCharts(charts...))
+	}
+	if len(conditionalSpecs) > 0 {
+		formats := make([]file.ConditionalFormat, len(conditionalSpecs))
+		for i, spec := range conditionalSpecs {
+			format, err := file.ParseConditionalFormat(spec)
+			if err != nil {
+				return nil, usageError("%w", err)
+			}
+			if err := format.Check(columns); err != nil {
+				return nil, usageError("%w", err)
+			}
+			formats[i] = format
+		}
+		options = append(options, file.WithConditionalFormats(formats...))
+	}
+	if len(dropdownColumns) > 0 {
+		var dropdowns []string
+		for _, name := range dropdownColumns {
+			if name == "auto" {
+				dropdowns = append(dropdowns, f.LowCardinalityColumns(dropdownMax)...)
+				continue
+			}
+			dropdowns = append(dropdowns, name)
+		}
+		if err := f.CheckDropdowns(dropdowns...); err != nil {
+			return nil, usageError("%w", err)
+		}
+		options = append(options, file.WithDropdowns(dropdowns...))
+	}
+	if blankRows < 0 {
+		return nil, usageError("blank-rows cannot be negative")
+	}
+	if blankRows > 0 {
+		options = append(options, file.WithBlankRows(blankRows))
+	}
+	if len(linkText) > 0 {
+		if err := f.CheckLinkText(linkText); err != nil {
+			return nil, usageError("%w", err)
+		}
+		options = append(options, file.WithLinkText(linkText))
+	}
+	props := properties
+	var err error
+	if props.Created, err = parseCreated(created); err != nil {
+		return nil, usageError("%w", err)
+	}
+	options = append(options, file.WithProperties(props))
+	if summarySheet {
+		options = append(options, file.WithSummary())
+	}
+	return options, nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/HampB/csv2excel/internal/file"
+)
+
+func Test_excelOptions(t *testing.T) {
+	defer func() {
+		formulaDefinitions, totalsFunctions, summarySheet, pivotSpecs, chartSpecs, conditionalSpecs = nil, nil, false, nil, nil, nil
+		dropdownColumns, blankRows, linkText, created = nil, 0, nil, ""
+	}()
+	f := file.New(file.WithHeaders([]file.Column{{Name: "Qty"}, {Name: "Price"}}))
+	tests := []struct {
+		name     string
+		formulas []string
+		totals   []string
+		summary  bool
+		pivots   []string
+		charts   []string
+		formats  []string
+		dropdown []string
+		blank    int
+		links    map[string]string
+		created  string
+		expected int
+		wantErr  bool
+	}{
+		{name: "No options", expected: 1},
+		{name: "All options", formulas: []string{"Total=[Qty]*[Price]"}, totals: []string{"sum", "count"}, summary: true, expected: 4},
+		{name: "Pivot on formula", formulas: []string{"Total=[Qty]*[Price]"}, pivots: []string{"rows=Price,values=sum(Total)"}, expected: 3},
+		{name: "Invalid pivot", pivots: []string{"rows=Price"}, wantErr: true},
+		{name: "Chart", charts: []string{"type=bar,x=Qty,y=Price"}, expected: 2},
+		{name: "Unknown chart column", charts: []string{"type=bar,x=Qty,y=Cost"}, wantErr: true},
+		{name: "Conditional formats", formats: []string{"Qty=data-bar", "Price=above(10)"}, expected: 2},
+		{name: "Unknown conditional format column", formats: []string{"Cost=data-bar"}, wantErr: true},
+		{name: "Dropdowns with blank rows", dropdown: []string{"auto", "Qty"}, blank: 10, expected: 3},
+		{name: "Unknown dropdown column", dropdown: []string{"Region"}, wantErr: true},
+		{name: "Negative blank rows", blank: -1, wantErr: true},
+		{name: "Link text on text column", links: map[string]string{"Qty": "Price"}, wantErr: true},
+		{name: "Unknown pivot column", pivots: []string{"rows=Region,values=Qty"}, wantErr: true},
+		{name: "Invalid formula", formulas: []string{"Total"}, wantErr: true},
+		{name: "Unknown formula column", formulas: []string{"Total=[Qty]*[Cost]"}, wantErr: true},
+		{name: "Created date", created: "2024-01-31", expected: 1},
+		{name: "Invalid created date", created: "yesterday", wantErr: true},
+		{name: "Invalid totals function", totals: []string{"median"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formulaDefinitions, totalsFunctions, summarySheet, pivotSpecs, chartSpecs, conditionalSpecs = tt.formulas, tt.totals, tt.summary, tt.pivots, tt.charts, tt.formats
+			dropdownColumns, blankRows, linkText, created = tt.dropdown, tt.blank, tt.links, tt.created
+			options, err := excelOptions(f)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("excelOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && exitCode(err) != exitUsage {
+				t.Errorf("excelOptions() exit code = %d, expected %d", exitCode(err), exitUsage)
+			}
+			if len(options) != tt.expected {
+				t.Errorf("excelOptions() returned %d options, expected %d", len(options), tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/HampB/csv2excel/internal/file"
+)
+
+// csvExtensions lists the file extensions that are accepted as CSV input.
+var csvExtensions = []string{".csv", ".csv.gz", ".csv.bz2", ".csv.zst"}
+
+// zipExtension is the extension of zip archives, which are merged as folders of CSV files.
+const zipExtension = ".zip"
+
+// isCSVFile reports whether the path has one of the accepted CSV file extensions.
+func isCSVFile(path string) bool {
+	for _, ext := range csvExtensions {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// trimCSVExtension returns the path without its CSV file extension.
+func trimCSVExtension(path string) string {
+	for _, ext := range csvExtensions {
+		if strings.HasSuffix(path, ext) {
+			return strings.TrimSuffix(path, ext)
+		}
+	}
+	return path
+}
+
+// expandArchives replaces the zip archives among the paths with the CSV files they contain.
+func expandArchives(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		if !strings.HasSuffix(path, zipExtension) {
+			files = append(files, path)
+			continue
+		}
+		members, err := file.ListZip(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive %s: %w", path, err)
+		}
+		for _, member := range members {
+			if isCSVFile(member) {
+				files = append(files, member)
+			}
+		}
+	}
+	return files, nil
+}
+
+// hasGlobMeta reports whether the path contains glob pattern characters.
+func hasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// expandFilePatterns expands the glob patterns among the paths into the files they match,
+// sorted by name. Paths without pattern characters are returned as is, and a pattern that
+// matches no files is an error.
+// Patterns support * and ? within a path segment, and ** to match any number of directories.
+func expandFilePatterns(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if !hasGlobMeta(path) {
+			files = append(files, path)
+			continue
+		}
+		matches, err := globFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", path)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// globFiles returns the files matching the pattern, sorted by name.
+func globFiles(pattern string) ([]string, error) {
+	if !strings.Contains(pattern, "**") {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		return matches, nil
+	}
+
+	// WalkDir returns clean paths, so the pattern must be clean to match them, e.g. without "./".
+	pattern = filepath.Clean(pattern)
+	re, err := globToRegexp(filepath.ToSlash(pattern))
+	if err != nil {
+		return nil, err
+	}
+	// Walk from the deepest directory that does not contain pattern characters.
+	var base []string
+	for _, segment := range strings.Split(filepath.ToSlash(pattern), "/") {
+		if hasGlobMeta(segment) {
+			break
+		}
+		base = append(base, segment)
+	}
+	root := strings.Join(base, "/")
+	if root == "" {
+		root = "."
+		if strings.HasPrefix(pattern, "/") {
+			root = "/"
+		}
+	}
+
+	var matches []string
+	err = filepath.WalkDir(filepath.FromSlash(root), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && re.MatchString(filepath.ToSlash(path)) {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// globToRegexp translates a glob pattern using slash separators into a regular expression.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					sb.WriteString("(.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				sb.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// matchesAnyPattern reports whether the path matches any of the glob patterns.
+// Patterns containing a slash are matched against the whole path, others only against the base name.
+func matchesAnyPattern(path string, patterns []string) (bool, error) {
+	path = filepath.ToSlash(path)
+	for _, pattern := range patterns {
+		re, err := globToRegexp(filepath.ToSlash(pattern))
+		if err != nil {
+			return false, err
+		}
+		target := path
+		if !strings.Contains(pattern, "/") {
+			target = filepath.Base(path)
+		}
+		if re.MatchString(target) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// filterFiles keeps the files that match any of the include patterns, if given,
+// and none of the exclude patterns. Paths are matched relative to root.
+func filterFiles(files []string, root string, include, exclude []string) ([]string, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return files, nil
+	}
+	var filtered []string
+	for _, f := range files {
+		rel := f
+		if root != "" {
+			if r, err := filepath.Rel(root, f); err == nil {
+				rel = r
+			}
+		}
+		if len(include) > 0 {
+			ok, err := matchesAnyPattern(rel, include)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		excluded, err := matchesAnyPattern(rel, exclude)
+		if err != nil {
+			return nil, err
+		}
+		if !excluded {
+			filtered = append(filtered, f)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// createTestTree creates the given files, relative to a new temporary directory, and returns the directory.
+func createTestTree(t *testing.T, files ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("id\n1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func Test_globToRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{pattern: "*.csv", path: "a.csv", match: true},
+		{pattern: "*.csv", path: "dir/a.csv", match: false},
+		{pattern: "data/**/2024-*.csv", path: "data/2024-01.csv", match: true},
+		{pattern: "data/**/2024-*.csv", path: "data/eu/se/2024-01.csv", match: true},
+		{pattern: "data/**/2024-*.csv", path: "data/eu/2023-01.csv", match: false},
+		{pattern: "file?.csv", path: "file1.csv", match: true},
+		{pattern: "file[!1].csv", path: "file1.csv", match: false},
+		{pattern: "file[12].csv", path: "file2.csv", match: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			re, err := globToRegexp(tt.pattern)
+			if err != nil {
+				t.Fatalf("globToRegexp() error = %v", err)
+			}
+			if got := re.MatchString(tt.path); got != tt.match {
+				t.Errorf("globToRegexp(%q).MatchString(%q) = %v, expected %v", tt.pattern, tt.path, got, tt.match)
+			}
+		})
+	}
+}
+
+func Test_expandFilePatterns(t *testing.T) {
+	dir := createTestTree(t, "data/2024-01.csv", "data/eu/2024-02.csv.gz", "data/eu/2023-12.csv", "data/us/2024-03.csv")
+	join := func(path string) string { return filepath.Join(dir, filepath.FromSlash(path)) }
+
+	got, err := expandFilePatterns([]string{join("data/**/2024-*.csv*"), join("data/*.csv"), "plain.csv"})
+	if err != nil {
+		t.Fatalf("expandFilePatterns() error = %v", err)
+	}
+	expected := []string{
+		join("data/2024-01.csv"),
+		join("data/eu/2024-02.csv.gz"),
+		join("data/us/2024-03.csv"),
+		join("data/2024-01.csv"),
+		"plain.csv",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expandFilePatterns() = %v, expected %v", got, expected)
+	}
+}
+
+func Test_expandFilePatterns_Relative(t *testing.T) {
+	dir := createTestTree(t, "data/a.csv", "data/eu/b.csv")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	got, err := expandFilePatterns([]string{"./data/**/*.csv"})
+	if err != nil {
+		t.Fatalf("expandFilePatterns() error = %v", err)
+	}
+	expected := []string{filepath.Join("data", "a.csv"), filepath.Join("data", "eu", "b.csv")}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expandFilePatterns() = %v, expected %v", got, expected)
+	}
+
+	if _, err := expandFilePatterns([]string{"./data/**/*.tsv"}); err == nil || !strings.Contains(err.Error(), "*.tsv") {
+		t.Errorf("expandFilePatterns() error = %v, expected an error naming the pattern", err)
+	}
+}
+
+func Test_createFileList_Filtered(t *testing.T) {
+	dir := createTestTree(t, "a.csv", "b.csv.gz", "notes.txt", "sub/c.csv", "sub/tmp_d.csv")
+	join := func(path string) string { return filepath.Join(dir, filepath.FromSlash(path)) }
+
+	tests := []struct {
+		name      string
+		recursive bool
+		include   []string
+		exclude   []string
+		expected  []string
+	}{
+		{
+			name:     "Top level only",
+			expected: []string{join("a.csv"), join("b.csv.gz")},
+		},
+		{
+			name:      "Recursive",
+			recursive: true,
+			expected:  []string{join("a.csv"), join("b.csv.gz"), join("sub/c.csv"), join("sub/tmp_d.csv")},
+		},
+		{
+			name:      "Recursive with include and exclude",
+			recursive: true,
+			include:   []string{"sub/*"},
+			exclude:   []string{"tmp_*"},
+			expected:  []string{join("sub/c.csv")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := createFileList(dir, tt.recursive)
+			if err != nil {
+				t.Fatalf("createFileList() error = %v", err)
+			}
+			got, err := filterFiles(files, dir, tt.include, tt.exclude)
+			if err != nil {
+				t.Fatalf("filterFiles() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("createFileList() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
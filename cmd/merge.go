@@ -1,11 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"unicode"
 
 	"github.com/HampB/csv2excel/internal/file"
 	"github.com/spf13/cobra"
@@ -13,8 +19,24 @@ import (
 
 // mergeCmd represents the merge command
 var (
-	inputFiles  []string
-	inputFolder string
+	inputFiles   []string
+	inputFolder  string
+	sortFiles    string
+	recursive    bool
+	includeFiles []string
+	excludeFiles []string
+	jobs         int
+	failFast     bool
+	skipBadFiles bool
+	reportFormat string
+	dedupe       bool
+	dedupeKeys   []string
+	keepRow      string
+
+	sourceColumn     bool
+	sourceColumnName string
+	sourcePath       string
+	sourceLine       bool
 
 	mergeCmd = &cobra.Command{
 		Use:   "merge",
@@ -23,50 +45,127 @@ var (
 You can specify individual CSV files or a folder containing CSV files. For example:
 
 csv2excel merge --files file1.csv,file2.csv --output result.xlsx
-csv2excel merge --folder /path/to/csvfiles --output result.xlsx`,
-		Run: func(cmd *cobra.Command, args []string) {
+csv2excel merge --folder /path/to/csvfiles --output result.xlsx
+
+Without --output or --name, the Excel file is named after the folder, or merged.xlsx for --files.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if delimiter == "" {
-				fmt.Println("Delimiter cannot be empty")
-				return
+				return usageError("delimiter cannot be empty")
 			}
 			delimiterRune := []rune(delimiter)[0]
+			// Without --output or --name, the Excel file is named after the input folder.
+			output := resolveOutputPath(mergeSource(inputFolder))
+			if err := checkOverwrite(output); err != nil {
+				return err
+			}
 
+			var err error
+			root := ""
 			if inputFolder != "" {
-				var err error
-				inputFiles, err = createFileList(inputFolder)
+				if err := validateChoice("sort-files", sortFiles, "name", "mtime", "natural"); err != nil {
+					return err
+				}
+				inputFiles, err = createFileList(inputFolder, recursive)
 				if err != nil {
-					fmt.Println(err)
-					return
+					return readError(err)
+				}
+				if err := sortFileList(inputFiles, sortFiles); err != nil {
+					return readError(err)
+				}
+				root = inputFolder
+			} else {
+				inputFiles, err = expandFilePatterns(inputFiles)
+				if err != nil {
+					return usageError("%w", err)
 				}
 			}
+			inputFiles, err = expandArchives(inputFiles)
+			if err != nil {
+				return readError(err)
+			}
+			inputFiles, err = filterFiles(inputFiles, root, includeFiles, excludeFiles)
+			if err != nil {
+				return usageError("%w", err)
+			}
+
+			var mergeOptions []file.MergeOption
+			if dedupe || len(dedupeKeys) > 0 {
+				if err := validateChoice("keep", keepRow, "first", "last"); err != nil {
+					return err
+				}
+				mergeOptions = append(mergeOptions, file.WithDedupe(dedupeKeys, keepRow == "last"))
+			}
+
+			if sourceColumn {
+				if err := validateChoice("source-path", sourcePath, "basename", "full"); err != nil {
+					return err
+				}
+				mergeOptions = append(mergeOptions, file.WithSourceColumn(sourceColumnName, sourcePath == "full"))
+			}
+			if sourceLine {
+				mergeOptions = append(mergeOptions, file.WithSourceLine("source_line"))
+			}
 
-			f, err := processFiles(inputFiles, delimiterRune)
+			if err := validateChoice("report", reportFormat, "none", "table", "json"); err != nil {
+				return err
+			}
 
+			f, report, err := processFiles(inputFiles, processOptions{
+				delimiter:    delimiterRune,
+				jobs:         jobs,
+				failFast:     failFast,
+				skipBadFiles: skipBadFiles,
+				mergeOptions: mergeOptions,
+			})
+			if reportErr := printReport(os.Stdout, reportFormat, report); reportErr != nil {
+				return usageError("%w", reportErr)
+			}
 			if err != nil {
-				fmt.Println(err)
-				return
+				return err
 			}
 
-			if convertTypes {
+			if err := applyColumnOptions(f); err != nil {
+				return usageError("%w", err)
+			}
+			if convertTypes || needsColumnTypes() {
 				f.InferColumnTypes()
 				f.ConvertColumnTypes()
 			}
-			if outputFile == "" && outputName == "" {
-				outputFile = strings.Replace(inputFile, ".csv", ".xlsx", 1)
+			if err := applyRowOptions(f); err != nil {
+				return usageError("%w", err)
 			}
-			if outputName != "" {
-				outputFile = filepath.Join(filepath.Dir(inputFile), outputName+".xlsx")
+			options, err := excelOptions(f)
+			if err != nil {
+				return err
 			}
-			if _, err := os.Stat(filepath.Dir(outputFile)); os.IsNotExist(err) {
-				fmt.Printf("Invalid output path: %s\n", filepath.Dir(outputFile))
-				return
+			if err := prepareOutputDir(filepath.Dir(output)); err != nil {
+				return err
+			}
+			if dryRun {
+				return printPreview(os.Stdout, f, output, previewRows)
 			}
-			err = f.SaveAsExcel(outputFile, "Sheet1")
+			err = f.SaveAsExcel(output, "Sheet1", options...)
 			if err != nil {
-				fmt.Println(err)
-				return
+				return writeError(err)
+			}
+			message := fmt.Sprintf("Successfully converted %d records with %d columns to %s", len(f.Records), len(f.Headers), output)
+			if dedupe || len(dedupeKeys) > 0 {
+				message += fmt.Sprintf(", dropped %d duplicate rows", report.DroppedRows)
+			}
+			failed, rejected := report.failedFiles(), report.rejectedRows()
+			if failed > 0 {
+				message += fmt.Sprintf(", skipped %d files with errors", failed)
+			}
+			if rejected > 0 {
+				message += fmt.Sprintf(", rejected %d malformed rows", rejected)
+			}
+			// Keep stdout for the report, so it can be piped to other tools.
+			out := os.Stdout
+			if reportFormat != "none" {
+				out = os.Stderr
 			}
-			fmt.Printf("Successfully converted %d records with %d columns to %s\n", len(f.Records), len(f.Headers), outputFile)
+			fmt.Fprintln(out, message)
+			return partialError(failed, len(report.Files), rejected)
 		},
 	}
 )
@@ -74,66 +173,183 @@ csv2excel merge --folder /path/to/csvfiles --output result.xlsx`,
 func init() {
 	rootCmd.AddCommand(mergeCmd)
 
-	mergeCmd.Flags().StringSliceVarP(&inputFiles, "files", "f", []string{}, "List of CSV files to merge")
+	mergeCmd.Flags().StringSliceVarP(&inputFiles, "files", "f", []string{}, "List of CSV files or glob patterns to merge, e.g. data/**/2024-*.csv")
 	mergeCmd.Flags().StringVarP(&inputFolder, "folder", "F", "", "Path to the folder containing CSV files")
+	mergeCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Include CSV files in subfolders of --folder")
+	mergeCmd.Flags().StringSliceVar(&includeFiles, "include-files", []string{}, "Only merge files matching these glob patterns")
+	mergeCmd.Flags().StringSliceVar(&excludeFiles, "exclude-files", []string{}, "Skip files matching these glob patterns")
+	mergeCmd.Flags().StringVar(&sortFiles, "sort-files", "name", "Order in which files in --folder are merged: name, mtime or natural")
 	mergeCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Path to the output Excel file")
+	addOutputFlags(mergeCmd)
 	mergeCmd.Flags().StringVarP(&delimiter, "delimiter", "d", ",", "Delimiter for CSV file")
 	mergeCmd.Flags().BoolVarP(&convertTypes, "convert", "c", false, "Convert column types to inferred types")
+	mergeCmd.Flags().IntVarP(&jobs, "jobs", "j", runtime.NumCPU(), "Maximum number of files to read concurrently")
+	mergeCmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop reading files after the first error")
+	mergeCmd.Flags().BoolVar(&skipBadFiles, "skip-bad-files", false, "Merge the files that can be read, skipping unreadable files and malformed rows")
+	mergeCmd.Flags().StringVar(&reportFormat, "report", "none", "Print a report of the files processed: none, table or json")
+	mergeCmd.Flags().BoolVar(&dedupe, "dedupe", false, "Remove duplicate rows from the merged data")
+	mergeCmd.Flags().StringSliceVar(&dedupeKeys, "dedupe-key", []string{}, "Columns identifying duplicate rows, implies --dedupe")
+	mergeCmd.Flags().StringVar(&keepRow, "keep", "first", "Which duplicate row to keep: first or last")
+	mergeCmd.Flags().BoolVar(&sourceColumn, "source", false, "Append a column with the file each row came from")
+	mergeCmd.Flags().StringVar(&sourceColumnName, "source-name", "source_file", "Name of the source file column")
+	mergeCmd.Flags().StringVar(&sourcePath, "source-path", "basename", "Source file column content: basename or full")
+	mergeCmd.Flags().BoolVar(&sourceLine, "source-line", false, "Append a source_line column with the line number each row came from")
+	addTransformFlags(mergeCmd)
+	addExcelFlags(mergeCmd)
+	addDryRunFlags(mergeCmd)
+	addOverwriteFlags(mergeCmd, "Never overwrite an existing output file")
 
 	mergeCmd.MarkFlagsOneRequired("files", "folder")
 	mergeCmd.MarkFlagsMutuallyExclusive("files", "folder")
-	mergeCmd.MarkFlagRequired("output")
+	mergeCmd.MarkFlagsMutuallyExclusive("fail-fast", "skip-bad-files")
+	mergeCmd.MarkFlagsMutuallyExclusive("output", "name")
 }
 
-// processFiles reads and processes multiple CSV files concurrently.
-// It takes a slice of file paths and a delimiter as input, and returns a merged CSV file or an error.
-func processFiles(filePaths []string, delimiter rune) (*file.CSV, error) {
-	wg := sync.WaitGroup{}
-	resultChannel := make(chan processResult)
+// partialError returns an error with the partial exit code describing the files that could
+// not be merged and the rows that were rejected, or nil if there were none.
+func partialError(failed, files, rejected int) error {
+	var problems []string
+	if failed > 0 {
+		problems = append(problems, fmt.Sprintf("%d of %d files could not be merged", failed, files))
+	}
+	if rejected > 0 {
+		problems = append(problems, fmt.Sprintf("%d rows were rejected", rejected))
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return &exitCodeError{code: exitPartial, err: errors.New(strings.Join(problems, " and "))}
+}
 
-	for _, filePath := range filePaths {
-		filePath = strings.TrimSpace(filePath)
-		if !strings.HasSuffix(filePath, ".csv") {
-			return nil, fmt.Errorf("invalid input file format. Please provide a CSV file")
+// mergeSource returns the source the merged Excel file is named after: the input folder,
+// or merged if there is no folder or it has no name of its own, like the root folder.
+// Folders such as "." or ".." are made absolute so they are named after the folder they refer to.
+func mergeSource(folder string) string {
+	if folder == "" {
+		return "merged"
+	}
+	source := filepath.Clean(folder)
+	if base := filepath.Base(source); base == "." || base == ".." {
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return "merged"
 		}
-		wg.Add(1)
-		go func(filePath string, delimiter rune) {
-			defer wg.Done()
-			f := file.New(
-				file.WithFilePath(filePath),
-				file.WithDelimiter(delimiter),
-			)
-			err := f.Read()
-			if err != nil {
-				resultChannel <- processResult{err: err}
-				return
-			}
-			resultChannel <- processResult{file: f}
-		}(filePath, delimiter)
+		source = abs
 	}
+	if base := filepath.Base(source); base == string(filepath.Separator) {
+		return "merged"
+	}
+	return source
+}
+
+// processOptions configures how processFiles reads and merges files.
+type processOptions struct {
+	// delimiter is the character used to separate fields in the CSV files.
+	delimiter rune
+	// jobs is the maximum number of files read concurrently.
+	jobs int
+	// failFast skips the files that have not started reading after the first error.
+	failFast bool
+	// skipBadFiles merges the files that could be read, skipping the others and any malformed rows.
+	skipBadFiles bool
+	// mergeOptions are passed on to the merger.
+	mergeOptions []file.MergeOption
+}
+
+// processFiles reads and processes multiple CSV files concurrently, using at most opts.jobs workers.
+// The records of each file are appended to the merged file as soon as all files before it have been
+// merged, so the output follows the order of filePaths. At most opts.jobs files are open or parsed and
+// waiting to be merged at once, but the merged file holds the records of every file until it is saved.
+// It returns the merged CSV file and a report of the files processed. The report is also returned,
+// as far as it got, along with an error.
+func processFiles(filePaths []string, opts processOptions) (*file.CSV, mergeReport, error) {
+	report := mergeReport{Files: make([]fileReport, 0, len(filePaths))}
+	for i, filePath := range filePaths {
+		filePaths[i] = strings.TrimSpace(filePath)
+		if !isCSVFile(filePaths[i]) {
+			return nil, report, usageError("invalid input file format. Please provide a CSV file")
+		}
+	}
+	jobs := max(opts.jobs, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var failure error
+	var failOnce sync.Once
+
+	// Each file has its own buffered result channel, so workers never block and results
+	// can be consumed in input order. A slot is held from the start of reading a file until
+	// its records have been merged, which bounds the number of open and parsed files.
+	results := make([]chan processResult, len(filePaths))
+	for i := range results {
+		results[i] = make(chan processResult, 1)
+	}
+	slots := make(chan struct{}, jobs)
 	go func() {
-		wg.Wait()
-		close(resultChannel)
+		for i, filePath := range filePaths {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func(index int, filePath string) {
+				f := file.New(
+					file.WithFilePath(filePath),
+					file.WithDelimiter(opts.delimiter),
+					file.WithSkipBadRows(opts.skipBadFiles),
+				)
+				err := f.Read()
+				if err != nil && opts.failFast {
+					failOnce.Do(func() {
+						failure = err
+						cancel()
+					})
+				}
+				results[index] <- processResult{file: f, err: err}
+			}(i, filePath)
+		}
 	}()
+
+	merger := file.NewMerger(opts.mergeOptions...)
 	var errors []error
-	var files = make([]*file.CSV, 0, len(filePaths))
-	for result := range resultChannel {
+	for i, filePath := range filePaths {
+		var result processResult
+		select {
+		case result = <-results[i]:
+		case <-ctx.Done():
+			return nil, report, readError(fmt.Errorf("stopped merging after error: %w", failure))
+		}
+		<-slots
+		entry := fileReport{File: filePath, RowsRead: len(result.file.Records), RowsRejected: result.file.RejectedRows}
+		if result.err == nil {
+			result.err = merger.Add(result.file)
+		}
 		if result.err != nil {
+			entry.RowsRead = 0
+			entry.Error = result.err.Error()
+			report.Files = append(report.Files, entry)
+			if opts.failFast {
+				return nil, report, readError(fmt.Errorf("stopped merging after error: %w", result.err))
+			}
 			errors = append(errors, result.err)
-		} else {
-			files = append(files, result.file)
+			continue
 		}
+		report.Files = append(report.Files, entry)
 	}
 
-	if len(errors) > 0 {
-		return nil, fmt.Errorf("encountered errors while reading files: %v", errors)
+	if len(errors) > 0 && !opts.skipBadFiles {
+		return nil, report, readError(fmt.Errorf("encountered errors while reading files: %v", errors), errors...)
 	}
 
-	if len(files) == 0 {
-		return nil, fmt.Errorf("no valid CSV files to merge")
+	if len(errors) == len(filePaths) {
+		return nil, report, readError(fmt.Errorf("no valid CSV files to merge"), errors...)
 	}
-	return file.Merge(files...)
-
+	f, dropped, err := merger.Result()
+	if err != nil {
+		return nil, report, usageError("%w", err)
+	}
+	report.DroppedRows = dropped
+	return f, report, nil
 }
 
 // processResult represents the result of processing a CSV file.
@@ -143,19 +359,99 @@ type processResult struct {
 	err  error
 }
 
-// createFileList scans the specified folder for files with a .csv extension
-// and returns a slice of their file paths. If an error occurs during reading
+// createFileList scans the specified folder, and its subfolders if recursive is true, for files with
+// a CSV or zip extension and returns a slice of their file paths. If an error occurs during reading
 // the directory, it returns the error.
-func createFileList(folderPath string) ([]string, error) {
+func createFileList(folderPath string, recursive bool) ([]string, error) {
 	var files []string
+	if recursive {
+		err := filepath.WalkDir(folderPath, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && (isCSVFile(d.Name()) || strings.HasSuffix(d.Name(), zipExtension)) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return files, nil
+	}
 	entries, err := os.ReadDir(folderPath)
 	if err != nil {
 		return nil, err
 	}
 	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".csv") {
+		if !entry.IsDir() && (isCSVFile(entry.Name()) || strings.HasSuffix(entry.Name(), zipExtension)) {
 			files = append(files, filepath.Join(folderPath, entry.Name()))
 		}
 	}
 	return files, nil
 }
+
+// sortFileList sorts the file paths in place by the given mode.
+// "name" sorts lexically, "natural" sorts numbers within names by value (file2 before file10)
+// and "mtime" sorts by modification time, oldest first.
+func sortFileList(files []string, mode string) error {
+	switch mode {
+	case "name":
+		sort.Strings(files)
+	case "natural":
+		sort.SliceStable(files, func(i, j int) bool {
+			return naturalLess(files[i], files[j])
+		})
+	case "mtime":
+		modTimes := make(map[string]int64, len(files))
+		for _, f := range files {
+			info, err := os.Stat(f)
+			if err != nil {
+				return err
+			}
+			modTimes[f] = info.ModTime().UnixNano()
+		}
+		sort.SliceStable(files, func(i, j int) bool {
+			if modTimes[files[i]] == modTimes[files[j]] {
+				return files[i] < files[j]
+			}
+			return modTimes[files[i]] < modTimes[files[j]]
+		})
+	default:
+		return fmt.Errorf("invalid sort mode: %s, expected name, mtime or natural", mode)
+	}
+	return nil
+}
+
+// naturalLess compares two strings, treating runs of digits as numbers.
+func naturalLess(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	i, j := 0, 0
+	for i < len(ra) && j < len(rb) {
+		if unicode.IsDigit(ra[i]) && unicode.IsDigit(rb[j]) {
+			si := i
+			for i < len(ra) && unicode.IsDigit(ra[i]) {
+				i++
+			}
+			sj := j
+			for j < len(rb) && unicode.IsDigit(rb[j]) {
+				j++
+			}
+			na := strings.TrimLeft(string(ra[si:i]), "0")
+			nb := strings.TrimLeft(string(rb[sj:j]), "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			continue
+		}
+		if ra[i] != rb[j] {
+			return ra[i] < rb[j]
+		}
+		i++
+		j++
+	}
+	return len(ra)-i < len(rb)-j
+}
@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HampB/csv2excel/internal/file"
+)
+
+// writeTestFiles creates count CSV files in dir, each holding a single record with the file number.
+func writeTestFiles(t *testing.T, dir string, count int) []string {
+	t.Helper()
+	paths := make([]string, count)
+	for i := range count {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("file%d.csv", i))
+		// Larger files first, so that the files are likely to finish reading out of order.
+		content := "id,padding\n" + fmt.Sprintf("%d,%0*d\n", i, (count-i)*1000, 0)
+		if err := os.WriteFile(paths[i], []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func Test_processFiles_Order(t *testing.T) {
+	paths := writeTestFiles(t, t.TempDir(), 50)
+	expected := make([]file.Value, len(paths))
+	for i := range paths {
+		expected[i] = fmt.Sprint(i)
+	}
+
+	for run := range 10 {
+		f, _, err := processFiles(paths, processOptions{delimiter: ',', jobs: 4})
+		if err != nil {
+			t.Fatalf("processFiles() error = %v", err)
+		}
+		got := make([]file.Value, len(f.Records))
+		for i, record := range f.Records {
+			got[i] = record[0]
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("processFiles() run %d order = %v, expected %v", run, got, expected)
+		}
+	}
+}
+
+func Test_partialError(t *testing.T) {
+	tests := []struct {
+		name     string
+		failed   int
+		rejected int
+		expected string
+	}{
+		{name: "No problems"},
+		{name: "Failed files", failed: 1, expected: "1 of 3 files could not be merged"},
+		{name: "Rejected rows", rejected: 2, expected: "2 rows were rejected"},
+		{name: "Both", failed: 1, rejected: 2, expected: "1 of 3 files could not be merged and 2 rows were rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := partialError(tt.failed, 3, tt.rejected)
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("partialError() = %v, expected nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.expected || exitCode(err) != exitPartial {
+				t.Errorf("partialError() = %v, expected %q with exit code %d", err, tt.expected, exitPartial)
+			}
+		})
+	}
+}
+
+func Test_mergeSource(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		folder   string
+		expected string
+	}{
+		{name: "No folder", folder: "", expected: "merged"},
+		{name: "Folder", folder: "data/2024/", expected: filepath.Join("data", "2024")},
+		{name: "Working directory", folder: ".", expected: wd},
+		{name: "Parent directory", folder: "..", expected: filepath.Dir(wd)},
+		{name: "Root", folder: string(filepath.Separator), expected: "merged"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeSource(tt.folder); got != tt.expected {
+				t.Errorf("mergeSource() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_sortFileList(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeTestFiles(t, dir, 11)
+	now := time.Now()
+	for i, path := range paths {
+		// Make the files older the higher their number.
+		modTime := now.Add(-time.Duration(i) * time.Minute)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	name := func(n int) string { return filepath.Join(dir, fmt.Sprintf("file%d.csv", n)) }
+
+	tests := []struct {
+		name     string
+		mode     string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "Sort by name",
+			mode:     "name",
+			expected: []string{name(0), name(1), name(10), name(2), name(3), name(4), name(5), name(6), name(7), name(8), name(9)},
+		},
+		{
+			name:     "Sort naturally",
+			mode:     "natural",
+			expected: []string{name(0), name(1), name(2), name(3), name(4), name(5), name(6), name(7), name(8), name(9), name(10)},
+		},
+		{
+			name:     "Sort by modification time",
+			mode:     "mtime",
+			expected: []string{name(10), name(9), name(8), name(7), name(6), name(5), name(4), name(3), name(2), name(1), name(0)},
+		},
+		{
+			name:    "Invalid mode",
+			mode:    "size",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := createFileList(dir, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = sortFileList(files, tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("sortFileList() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(files, tt.expected) {
+				t.Errorf("sortFileList() = %v, expected %v", files, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_naturalLess(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{a: "file2.csv", b: "file10.csv", expected: true},
+		{a: "file10.csv", b: "file2.csv", expected: false},
+		{a: "file02.csv", b: "file3.csv", expected: true},
+		{a: "a.csv", b: "b.csv", expected: true},
+		{a: "file", b: "file1", expected: true},
+		{a: "file1", b: "file1", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"<"+tt.b, func(t *testing.T) {
+			if got := naturalLess(tt.a, tt.b); got != tt.expected {
+				t.Errorf("naturalLess(%q, %q) = %v, expected %v", tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_processFiles_Errors(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeTestFiles(t, dir, 20)
+	paths[3] = filepath.Join(dir, "missing.csv")
+
+	for _, failFast := range []bool{false, true} {
+		t.Run(fmt.Sprintf("failFast=%v", failFast), func(t *testing.T) {
+			_, _, err := processFiles(slices.Clone(paths), processOptions{delimiter: ',', jobs: 2, failFast: failFast})
+			if err == nil {
+				t.Fatalf("processFiles() expected error for missing file")
+			}
+			if !strings.Contains(err.Error(), "missing.csv") {
+				t.Errorf("processFiles() error = %v, expected it to mention missing.csv", err)
+			}
+		})
+	}
+}
+
+func Test_processFiles_SkipBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeTestFiles(t, dir, 3)
+	paths[1] = filepath.Join(dir, "missing.csv")
+	bad := filepath.Join(dir, "bad.csv")
+	if err := os.WriteFile(bad, []byte("id,padding\n3,0\n4\n5,0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	paths = append(paths, bad)
+
+	f, report, err := processFiles(paths, processOptions{delimiter: ',', jobs: 2, skipBadFiles: true})
+	if err != nil {
+		t.Fatalf("processFiles() error = %v", err)
+	}
+	if len(f.Records) != 4 {
+		t.Errorf("processFiles() records = %d, expected 4", len(f.Records))
+	}
+	if report.failedFiles() != 1 {
+		t.Errorf("failedFiles() = %d, expected 1", report.failedFiles())
+	}
+	expected := []fileReport{
+		{File: paths[0], RowsRead: 1},
+		{File: paths[1], Error: report.Files[1].Error},
+		{File: paths[2], RowsRead: 1},
+		{File: bad, RowsRead: 2, RowsRejected: 1},
+	}
+	if !reflect.DeepEqual(report.Files, expected) {
+		t.Errorf("processFiles() report = %+v, expected %+v", report.Files, expected)
+	}
+	if report.Files[1].Error == "" {
+		t.Errorf("processFiles() expected an error for the missing file")
+	}
+}
+
+func Test_printReport(t *testing.T) {
+	report := mergeReport{
+		Files: []fileReport{
+			{File: "a.csv", RowsRead: 10, RowsRejected: 1},
+			{File: "b.csv", Error: "open b.csv: no such file or directory"},
+		},
+	}
+	tests := []struct {
+		format   string
+		expected string
+		wantErr  bool
+	}{
+		{format: "none", expected: ""},
+		{
+			format: "table",
+			expected: "FILE   ROWS READ  ROWS REJECTED  ERROR\n" +
+				"a.csv  10         1              \n" +
+				"b.csv  0          0              open b.csv: no such file or directory\n",
+		},
+		{
+			format: "json",
+			expected: `{
+  "files": [
+    {
+      "file": "a.csv",
+      "rows_read": 10,
+      "rows_rejected": 1
+    },
+    {
+      "file": "b.csv",
+      "rows_read": 0,
+      "rows_rejected": 0,
+      "error": "open b.csv: no such file or directory"
+    }
+  ],
+  "dropped_duplicate_rows": 0
+}
+`,
+		},
+		{format: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var sb strings.Builder
+			err := printReport(&sb, tt.format, report)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("printReport() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got := sb.String(); got != tt.expected {
+				t.Errorf("printReport() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	forceOverwrite bool
+	noClobber      bool
+	makeDirs       bool
+)
+
+// addOutputFlags registers the flags controlling where output files are written.
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputName, "name", "n", "", "Name of the output Excel file, may contain {basename}, {date} and {time}")
+	cmd.Flags().StringVar(&outputDir, "out-dir", "", "Folder to write the Excel files to, defaults to the folder of the input")
+	cmd.Flags().BoolVar(&makeDirs, "mkdir", false, "Create the output folder if it does not exist")
+}
+
+// resolveOutputPath returns the path of the Excel file written for source, which is the input file,
+// or the input folder when merging. The path is taken from --output if given, otherwise it is --name,
+// or the name of source with an .xlsx extension, in --out-dir or the folder containing source.
+// --output and --name may contain the placeholders {basename}, {date} and {time}.
+func resolveOutputPath(source string) string {
+	basename := filepath.Base(trimCSVExtension(source))
+	dir := filepath.Dir(source)
+	if strings.HasSuffix(dir, zipExtension) {
+		// Files inside an archive are written next to the archive.
+		dir = filepath.Dir(dir)
+	}
+	if outputDir != "" {
+		dir = outputDir
+	}
+
+	now := time.Now()
+	switch {
+	case outputFile != "":
+		path := expandOutputTemplate(outputFile, basename, now)
+		if outputDir != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(outputDir, path)
+		}
+		return path
+	case outputName != "":
+		name := expandOutputTemplate(outputName, basename, now)
+		if filepath.Ext(name) != ".xlsx" {
+			name += ".xlsx"
+		}
+		return filepath.Join(dir, name)
+	}
+	return filepath.Join(dir, basename+".xlsx")
+}
+
+// checkNameTemplate returns a usage error if --name is used for more than one input without
+// {basename}, as every input would then be written to the same Excel file.
+func checkNameTemplate(inputs int) error {
+	if inputs > 1 && outputName != "" && !strings.Contains(outputName, "{basename}") {
+		return usageError("--name must contain {basename} when converting more than one file")
+	}
+	return nil
+}
+
+// expandOutputTemplate replaces the placeholders in an output file name.
+func expandOutputTemplate(template, basename string, t time.Time) string {
+	return strings.NewReplacer(
+		"{basename}", basename,
+		"{date}", t.Format(time.DateOnly),
+		"{time}", t.Format("150405"),
+	).Replace(template)
+}
+
+// prepareOutputDir checks that the folder exists, creating it with --mkdir.
+// Nothing is created with --dry-run.
+func prepareOutputDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err == nil {
+		if !info.IsDir() {
+			return writeError(fmt.Errorf("output path %s is not a folder", dir))
+		}
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return writeError(err)
+	}
+	if !makeDirs {
+		return writeError(fmt.Errorf("output folder %s does not exist, use --mkdir to create it", dir))
+	}
+	if dryRun {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return writeError(err)
+	}
+	return nil
+}
+
+// addOverwriteFlags registers the flags controlling whether existing output files are replaced.
+func addOverwriteFlags(cmd *cobra.Command, usage string) {
+	cmd.Flags().BoolVar(&forceOverwrite, "force", false, "Overwrite existing output files without asking")
+	cmd.Flags().BoolVar(&noClobber, "no-clobber", false, usage)
+	cmd.MarkFlagsMutuallyExclusive("force", "no-clobber")
+}
+
+// checkOverwrite returns an error if the output file exists and may not be replaced.
+// Existing files are replaced with --force and never replaced with --no-clobber. Otherwise the
+// user is asked for confirmation if stdin is a terminal, and the file is not replaced if it is not.
+func checkOverwrite(path string) error {
+	if forceOverwrite || dryRun {
+		return nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	if !noClobber && isTerminal(os.Stdin) {
+		ok, err := confirmOverwrite(path, os.Stdin, os.Stderr)
+		if err != nil || ok {
+			return err
+		}
+	}
+	return writeError(fmt.Errorf("output file %s already exists, use --force to overwrite it", path))
+}
+
+// confirmOverwrite asks whether the file may be overwritten and reports whether the answer was yes.
+func confirmOverwrite(path string, in io.Reader, out io.Writer) (bool, error) {
+	fmt.Fprintf(out, "%s already exists, overwrite it? [y/N] ", path)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// isTerminal reports whether the file is an interactive terminal. Other character devices,
+// such as /dev/null, are not terminals.
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_resolveOutputPath(t *testing.T) {
+	defer func() { outputFile, outputName, outputDir = "", "", "" }()
+	date := time.Now().Format(time.DateOnly)
+	tests := []struct {
+		name     string
+		output   string
+		outName  string
+		dir      string
+		source   string
+		expected string
+	}{
+		{name: "Default", source: filepath.Join("data", "a.csv.gz"), expected: filepath.Join("data", "a.xlsx")},
+		{name: "Output directory", dir: "out", source: filepath.Join("data", "a.csv"), expected: filepath.Join("out", "a.xlsx")},
+		{name: "Output", output: "result.xlsx", source: filepath.Join("data", "a.csv"), expected: "result.xlsx"},
+		{name: "Output in directory", output: "result.xlsx", dir: "out", source: "a.csv", expected: filepath.Join("out", "result.xlsx")},
+		{name: "Name", outName: "result", source: filepath.Join("data", "a.csv"), expected: filepath.Join("data", "result.xlsx")},
+		{name: "Name template", outName: "{basename}_{date}.xlsx", dir: "out", source: filepath.Join("data", "a.csv"), expected: filepath.Join("out", "a_"+date+".xlsx")},
+		{name: "Zip member", source: filepath.Join("data", "archive.zip", "a.csv"), expected: filepath.Join("data", "a.xlsx")},
+		{name: "Merged folder", source: filepath.Join("data", "2024"), expected: filepath.Join("data", "2024.xlsx")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFile, outputName, outputDir = tt.output, tt.outName, tt.dir
+			if got := resolveOutputPath(tt.source); got != tt.expected {
+				t.Errorf("resolveOutputPath() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_checkNameTemplate(t *testing.T) {
+	defer func() { outputName = "" }()
+	tests := []struct {
+		name    string
+		outName string
+		inputs  int
+		wantErr bool
+	}{
+		{name: "No name", inputs: 2},
+		{name: "Single input", outName: "report", inputs: 1},
+		{name: "Multiple inputs", outName: "report", inputs: 2, wantErr: true},
+		{name: "Multiple inputs with basename", outName: "{basename}_report", inputs: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputName = tt.outName
+			err := checkNameTemplate(tt.inputs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkNameTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && exitCode(err) != exitUsage {
+				t.Errorf("exitCode() = %v, expected %v", exitCode(err), exitUsage)
+			}
+		})
+	}
+}
+
+func Test_expandOutputTemplate(t *testing.T) {
+	now := time.Date(2024, 3, 9, 14, 5, 7, 0, time.UTC)
+	got := expandOutputTemplate("{basename}_{date}_{time}.xlsx", "sales", now)
+	if expected := "sales_2024-03-09_140507.xlsx"; got != expected {
+		t.Errorf("expandOutputTemplate() = %v, expected %v", got, expected)
+	}
+}
+
+func Test_prepareOutputDir(t *testing.T) {
+	defer func() { makeDirs = false }()
+	dir := filepath.Join(t.TempDir(), "a", "b")
+
+	makeDirs = false
+	if err := prepareOutputDir(dir); err == nil || exitCode(err) != exitWrite {
+		t.Errorf("prepareOutputDir() error = %v, expected write error", err)
+	}
+	makeDirs = true
+	if err := prepareOutputDir(dir); err != nil {
+		t.Fatalf("prepareOutputDir() error = %v", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("prepareOutputDir() did not create %s", dir)
+	}
+}
+
+func Test_checkOverwrite(t *testing.T) {
+	defer func() { forceOverwrite, noClobber = false, false }()
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.xlsx")
+	if err := os.WriteFile(existing, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		force     bool
+		noClobber bool
+		wantErr   bool
+	}{
+		{name: "New file", path: filepath.Join(dir, "new.xlsx")},
+		{name: "Existing file without terminal", path: existing, wantErr: true},
+		{name: "Existing file with force", path: existing, force: true},
+		{name: "Existing file with no-clobber", path: existing, noClobber: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forceOverwrite, noClobber = tt.force, tt.noClobber
+			err := checkOverwrite(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkOverwrite() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && exitCode(err) != exitWrite {
+				t.Errorf("checkOverwrite() exit code = %d, expected %d", exitCode(err), exitWrite)
+			}
+		})
+	}
+}
+
+func Test_confirmOverwrite(t *testing.T) {
+	tests := []struct {
+		answer   string
+		expected bool
+	}{
+		{answer: "y\n", expected: true},
+		{answer: "Yes\n", expected: true},
+		{answer: "n\n", expected: false},
+		{answer: "\n", expected: false},
+		{answer: "", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.answer, func(t *testing.T) {
+			var out strings.Builder
+			got, err := confirmOverwrite("out.xlsx", strings.NewReader(tt.answer), &out)
+			if err != nil {
+				t.Fatalf("confirmOverwrite() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("confirmOverwrite() = %v, expected %v", got, tt.expected)
+			}
+			if !strings.Contains(out.String(), "out.xlsx already exists") {
+				t.Errorf("confirmOverwrite() prompt = %q", out.String())
+			}
+		})
+	}
+}
+
+func Test_isTerminal(t *testing.T) {
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	if isTerminal(devNull) {
+		t.Errorf("isTerminal(%s) = true, expected false", os.DevNull)
+	}
+}
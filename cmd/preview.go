@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/HampB/csv2excel/internal/file"
+	"github.com/spf13/cobra"
+)
+
+// maxPreviewWidth is the maximum number of characters of a value shown in the preview.
+const maxPreviewWidth = 40
+
+var (
+	dryRun      bool
+	previewRows int
+)
+
+// addDryRunFlags registers the flags for previewing a conversion instead of writing the Excel file.
+func addDryRunFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the output path, columns and first rows instead of writing the Excel file")
+	cmd.Flags().IntVar(&previewRows, "preview-rows", 10, "Number of rows to print with --dry-run")
+}
+
+// printPreview writes the resolved output path, the detected columns and types, the number of
+// rows and the first rows of the data to w. The column types are inferred if they have not been.
+func printPreview(w io.Writer, f *file.CSV, outputPath string, rows int) error {
+	if !convertTypes {
+		f.InferColumnTypes()
+	}
+	fmt.Fprintf(w, "Output: %s\n", outputPath)
+	fmt.Fprintf(w, "Rows: %d\n", len(f.Records))
+	fmt.Fprintf(w, "Columns: %d\n", len(f.Headers))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, column := range f.Headers {
+		fmt.Fprintf(tw, "  %d\t%s\t%s\n", i+1, column.Name, column.Type)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	rows = min(max(rows, 0), len(f.Records))
+	if rows == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "First %d rows:\n", rows)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(previewCells(f.GetHeaderNames()), "\t"))
+	for _, record := range f.Records[:rows] {
+		values := make([]string, len(record))
+		for i, value := range record {
+			values[i] = file.FormatValue(value)
+		}
+		fmt.Fprintln(tw, strings.Join(previewCells(values), "\t"))
+	}
+	return tw.Flush()
+}
+
+// previewCells prepares values for the preview table, replacing line breaks and tabs
+// and truncating long values.
+func previewCells(values []string) []string {
+	cells := make([]string, len(values))
+	for i, value := range values {
+		value = strings.NewReplacer("\r\n", " ", "\n", " ", "\t", " ").Replace(value)
+		if runes := []rune(value); len(runes) > maxPreviewWidth {
+			value = string(runes[:maxPreviewWidth-3]) + "..."
+		}
+		cells[i] = value
+	}
+	return cells
+}
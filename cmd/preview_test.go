@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/HampB/csv2excel/internal/file"
+)
+
+func Test_printPreview(t *testing.T) {
+	f := file.New(
+		file.WithHeaders([]file.Column{
+			{Name: "id", Type: file.StringType},
+			{Name: "comment", Type: file.StringType},
+		}),
+		file.WithRecords([][]file.Value{
+			{"1", "short"},
+			{"2", "a comment that is much longer than the preview width\nwith a line break"},
+			{"3", ""},
+		}),
+	)
+
+	var sb strings.Builder
+	if err := printPreview(&sb, f, "out.xlsx", 2); err != nil {
+		t.Fatalf("printPreview() error = %v", err)
+	}
+	expected := `Output: out.xlsx
+Rows: 3
+Columns: 2
+  1  id       integer
+  2  comment  string
+First 2 rows:
+id  comment
+1   short
+2   a comment that is much longer than th...
+`
+	if got := sb.String(); got != expected {
+		t.Errorf("printPreview() =\n%s\nexpected\n%s", got, expected)
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// mergeReport summarises the files processed by the merge command.
+type mergeReport struct {
+	Files       []fileReport `json:"files"`
+	DroppedRows int          `json:"dropped_duplicate_rows"`
+}
+
+// fileReport holds the outcome of reading a single file.
+type fileReport struct {
+	File         string `json:"file"`
+	RowsRead     int    `json:"rows_read"`
+	RowsRejected int    `json:"rows_rejected"`
+	Error        string `json:"error,omitempty"`
+}
+
+// failedFiles returns the number of files that could not be merged.
+func (r mergeReport) failedFiles() int {
+	failed := 0
+	for _, f := range r.Files {
+		if f.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
+
+// rejectedRows returns the total number of malformed rows skipped.
+func (r mergeReport) rejectedRows() int {
+	rejected := 0
+	for _, f := range r.Files {
+		rejected += f.RowsRejected
+	}
+	return rejected
+}
+
+// printReport writes the report to w in the given format: none, table or json.
+func printReport(w io.Writer, format string, report mergeReport) error {
+	switch format {
+	case "none":
+		return nil
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "FILE\tROWS READ\tROWS REJECTED\tERROR")
+		for _, f := range report.Files {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", f.File, f.RowsRead, f.RowsRejected, f.Error)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("invalid report format: %s, expected none, table or json", format)
+}
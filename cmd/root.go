@@ -3,10 +3,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
-
-	"github.com/HampB/csv2excel/internal/file"
 
 	"github.com/spf13/cobra"
 )
@@ -24,64 +20,57 @@ var (
 		Short: "Convert CSV files to Excel format",
 		Long: `csv2excel is a CLI tool that allows you to convert CSV files to Excel format.
 You can specify the input CSV file, output Excel file, and the delimiter used in the CSV file.`,
-		Run: func(cmd *cobra.Command, args []string) {
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		PersistentPreRunE: loadConfigFlags,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if delimiter == "" {
-				fmt.Println("Delimiter cannot be empty")
-				return
+				return usageError("delimiter cannot be empty")
 			}
 			delimiterRune := []rune(delimiter)[0]
-			if !strings.HasSuffix(inputFile, ".csv") {
-				fmt.Println("Invalid input file format. Please provide a CSV file.")
-				return
-			}
-			f := file.New(
-				file.WithFilePath(inputFile),
-				file.WithDelimiter(delimiterRune),
-			)
-			err := f.Read()
-			if err != nil {
-				fmt.Println(err)
-				return
-			}
-			if convertTypes {
-				f.InferColumnTypes()
-				f.ConvertColumnTypes()
+			if !isCSVFile(inputFile) {
+				return usageError("invalid input file format. Please provide a CSV file")
 			}
-			if outputFile == "" && outputName == "" {
-				outputFile = strings.Replace(inputFile, ".csv", ".xlsx", 1)
+			output := resolveOutputPath(inputFile)
+			if err := checkOverwrite(output); err != nil {
+				return err
 			}
-			if outputName != "" {
-				outputFile = filepath.Join(filepath.Dir(inputFile), outputName+".xlsx")
-			}
-			if _, err := os.Stat(filepath.Dir(outputFile)); os.IsNotExist(err) {
-				fmt.Printf("Invalid output path: %s\n", filepath.Dir(outputFile))
-				return
-			}
-			err = f.SaveAsExcel(outputFile, "Sheet1")
+			f, err := convertFile(inputFile, output, delimiterRune)
 			if err != nil {
-				fmt.Println(err)
-				return
+				return err
+			}
+			if dryRun {
+				return nil
 			}
-			fmt.Printf("Successfully converted %d records with %d columns to %s\n", len(f.Records), len(f.Headers), outputFile)
+			fmt.Printf("Successfully converted %d records with %d columns to %s\n", len(f.Records), len(f.Headers), output)
+			return nil
 		},
 	}
 )
 
+// Execute runs the command given on the command line. Errors are printed to stderr and the
+// process exits with a code describing the kind of failure, see the exit* constants.
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitCode(err))
 	}
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Path to the config file, defaults to ./csv2excel.yaml and $XDG_CONFIG_HOME/csv2excel/csv2excel.yaml")
+	rootCmd.PersistentFlags().StringVarP(&profileName, "profile", "p", "", "Name of the config profile to use")
 
 	rootCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Path to the input CSV file")
 	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Path to the output Excel file")
-	rootCmd.Flags().StringVarP(&outputName, "name", "n", "", "Name of the output Excel file")
 	rootCmd.Flags().StringVarP(&delimiter, "delimiter", "d", ",", "Delimiter for CSV file")
 	rootCmd.Flags().BoolVarP(&convertTypes, "convert", "c", false, "Convert column types to inferred types")
+	addOutputFlags(rootCmd)
+	addTransformFlags(rootCmd)
+	addExcelFlags(rootCmd)
+	addDryRunFlags(rootCmd)
+	addOverwriteFlags(rootCmd, "Never overwrite an existing output file")
 
 	rootCmd.MarkFlagRequired("input")
 	rootCmd.MarkFlagFilename("input", "csv")
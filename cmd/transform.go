@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/HampB/csv2excel/internal/file"
+	"github.com/spf13/cobra"
+)
+
+var (
+	selectColumns  []string
+	excludeColumns []string
+	renameColumns  map[string]string
+	whereFilter    string
+	sortKeys       []string
+	nullsPlacement string
+)
+
+// addTransformFlags registers the flags shared by all commands that transform the CSV data before it is written.
+func addTransformFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&selectColumns, "columns", []string{}, "Columns to keep, in output order, by name, 1-based index or /regex/")
+	cmd.Flags().StringSliceVar(&excludeColumns, "exclude", []string{}, "Columns to remove, by name, 1-based index or /regex/")
+	cmd.Flags().StringToStringVar(&renameColumns, "rename", map[string]string{}, "Rename columns, e.g. --rename old=new")
+	cmd.Flags().StringSliceVar(&sortKeys, "sort", []string{}, "Sort rows by columns, prefix a column with - for descending order, e.g. --sort country,-amount")
+	cmd.Flags().StringVar(&nullsPlacement, "nulls", "last", "Placement of empty values when sorting: first or last")
+	cmd.Flags().StringVar(&whereFilter, "where", "", `Only keep rows matching the expression, e.g. 'amount > 100 && country == "SE"'`)
+}
+
+// applyColumnOptions selects, excludes and renames columns according to the command line flags.
+func applyColumnOptions(f *file.CSV) error {
+	if len(selectColumns) > 0 {
+		if err := f.SelectColumns(selectColumns...); err != nil {
+			return err
+		}
+	}
+	if len(excludeColumns) > 0 {
+		if err := f.ExcludeColumns(excludeColumns...); err != nil {
+			return err
+		}
+	}
+	if len(renameColumns) > 0 {
+		if err := f.RenameColumns(renameColumns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyRowOptions filters and sorts the records according to the command line flags.
+// It is applied after the column types have been converted, so comparisons are type aware.
+func applyRowOptions(f *file.CSV) error {
+	if whereFilter != "" {
+		if err := f.Filter(whereFilter); err != nil {
+			return err
+		}
+	}
+	if len(sortKeys) > 0 {
+		if nullsPlacement != "first" && nullsPlacement != "last" {
+			return fmt.Errorf("invalid value for --nulls: %s, expected first or last", nullsPlacement)
+		}
+		keys, err := file.ParseSortKeys(sortKeys)
+		if err != nil {
+			return err
+		}
+		if !convertTypes {
+			// Sort by the inferred types, but keep the columns as text in the output as without --convert.
+			headers := slices.Clone(f.Headers)
+			f.InferColumnTypes()
+			defer func() { f.Headers = headers }()
+		}
+		if err := f.Sort(keys, nullsPlacement == "first"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
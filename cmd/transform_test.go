@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/HampB/csv2excel/internal/file"
+)
+
+func Test_applyRowOptions_SortKeepsColumnTypes(t *testing.T) {
+	defer func() { sortKeys, nullsPlacement, convertTypes = nil, "last", false }()
+	sortKeys, nullsPlacement, convertTypes = []string{"amount"}, "last", false
+	headers := []file.Column{{Name: "url", Type: file.StringType}, {Name: "amount", Type: file.StringType}}
+	f := file.New(
+		file.WithHeaders(append([]file.Column(nil), headers...)),
+		file.WithRecords([][]file.Value{{"https://example.com/a", "10"}, {"https://example.com/b", "5"}}),
+	)
+
+	if err := applyRowOptions(f); err != nil {
+		t.Fatalf("applyRowOptions() error = %v", err)
+	}
+	if got := []file.Value{f.Records[0][1], f.Records[1][1]}; !reflect.DeepEqual(got, []file.Value{"5", "10"}) {
+		t.Errorf("applyRowOptions() amounts = %v, expected numeric order", got)
+	}
+	if !reflect.DeepEqual(f.Headers, headers) {
+		t.Errorf("applyRowOptions() headers = %v, expected %v", f.Headers, headers)
+	}
+}
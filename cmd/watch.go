@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// watchCmd represents the watch command
+var (
+	watchFolder   string
+	archiveFolder string
+	pollInterval  time.Duration
+
+	watchCmd = &cobra.Command{
+		Use:   "watch",
+		Short: "Convert CSV files as they appear in a folder",
+		Long: `The watch command monitors a folder and converts each new or changed CSV file to an Excel file
+once it has been fully written, that is once its size has stopped changing between two polls.
+Converted files can optionally be moved to an archive folder. Stop watching with Ctrl+C. For example:
+
+csv2excel watch --folder in --out-dir out
+csv2excel watch --folder in --out-dir out --archive processed --interval 10s`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if delimiter == "" {
+				return usageError("delimiter cannot be empty")
+			}
+			if pollInterval <= 0 {
+				return usageError("interval must be positive")
+			}
+			// Every file dropped in the folder is converted, so --name must differ per file.
+			if err := checkNameTemplate(2); err != nil {
+				return err
+			}
+			if outputDir != "" {
+				if err := prepareOutputDir(outputDir); err != nil {
+					return err
+				}
+			}
+			for _, dir := range []string{watchFolder, archiveFolder} {
+				if dir == "" {
+					continue
+				}
+				if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+					return readError(fmt.Errorf("invalid folder: %s", dir))
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			w := newWatcher(watchFolder, archiveFolder, []rune(delimiter)[0])
+			fmt.Printf("Watching %s for CSV files, press Ctrl+C to stop\n", watchFolder)
+			ticker := time.NewTicker(pollInterval)
+			defer ticker.Stop()
+			for {
+				if err := w.poll(); err != nil {
+					return readError(err)
+				}
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().StringVarP(&watchFolder, "folder", "F", "", "Path to the folder to watch for CSV files")
+	addOutputFlags(watchCmd)
+	watchCmd.Flags().StringVar(&archiveFolder, "archive", "", "Folder to move CSV files to once they have been converted")
+	watchCmd.Flags().DurationVar(&pollInterval, "interval", 2*time.Second, "How often to check the folder for new or changed files")
+	watchCmd.Flags().StringVarP(&delimiter, "delimiter", "d", ",", "Delimiter for CSV file")
+	watchCmd.Flags().BoolVarP(&convertTypes, "convert", "c", false, "Convert column types to inferred types")
+	addTransformFlags(watchCmd)
+	addExcelFlags(watchCmd)
+	watchCmd.Flags().BoolVar(&noClobber, "no-clobber", false, "Never overwrite existing Excel files; by default Excel files older than their CSV file are overwritten without asking")
+
+	watchCmd.MarkFlagRequired("folder")
+}
+
+// fileState is the size and modification time of a file when it was last seen.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// watcher converts the CSV files in a folder that have stopped changing.
+type watcher struct {
+	folder    string
+	archive   string
+	delimiter rune
+	// seen holds the state of each file at the previous poll, to tell whether it is still being written.
+	seen map[string]fileState
+	// failed holds the state of each file that failed to convert, so it is only retried once it changes.
+	failed map[string]fileState
+}
+
+// newWatcher creates a watcher for the folder, moving converted files to archive if it is not empty.
+func newWatcher(folder, archive string, delimiter rune) *watcher {
+	return &watcher{
+		folder:    folder,
+		archive:   archive,
+		delimiter: delimiter,
+		seen:      make(map[string]fileState),
+		failed:    make(map[string]fileState),
+	}
+}
+
+// poll scans the folder once and converts the files whose size and modification time are
+// unchanged since the previous poll and whose Excel file is missing or older than the file.
+// Conversion failures are printed and do not stop the watcher; only failing to read the folder
+// returns an error.
+func (w *watcher) poll() error {
+	entries, err := os.ReadDir(w.folder)
+	if err != nil {
+		return err
+	}
+	current := make(map[string]fileState)
+	for _, entry := range entries {
+		if entry.IsDir() || !isCSVFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(w.folder, entry.Name())
+		state := fileState{size: info.Size(), modTime: info.ModTime()}
+		current[path] = state
+
+		if previous, ok := w.seen[path]; !ok || previous != state {
+			continue
+		}
+		if failed, ok := w.failed[path]; ok && failed == state {
+			continue
+		}
+		output := resolveOutputPath(path)
+		if upToDate(path, output) || (noClobber && exists(output)) {
+			continue
+		}
+		w.convert(path, output, state)
+	}
+	w.seen = current
+	return nil
+}
+
+// convert converts a single file and archives it if configured to.
+func (w *watcher) convert(path, output string, state fileState) {
+	f, err := convertFile(path, output, w.delimiter)
+	if err != nil {
+		w.failed[path] = state
+		fmt.Fprintf(os.Stderr, "Failed to convert %s: %v\n", path, err)
+		return
+	}
+	delete(w.failed, path)
+	fmt.Printf("Converted %d records from %s to %s\n", len(f.Records), path, output)
+
+	if w.archive == "" {
+		return
+	}
+	archived, err := archivePath(w.archive, path, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to archive %s: %v\n", path, err)
+		return
+	}
+	if err := os.Rename(path, archived); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to archive %s: %v\n", path, err)
+		return
+	}
+	if filepath.Base(archived) != filepath.Base(path) {
+		fmt.Printf("Archived %s to %s, as a file with the same name was already archived\n", path, archived)
+		return
+	}
+	fmt.Printf("Archived %s to %s\n", path, archived)
+}
+
+// archivePath returns the path to move the file to in the archive folder. If a file with the same
+// name has already been archived, the time is added to the name, e.g. data_20240131-090000.csv,
+// and an error is returned if that file exists too, so archived files are never replaced.
+func archivePath(archive, path string, now time.Time) (string, error) {
+	name := filepath.Base(path)
+	archived := filepath.Join(archive, name)
+	if _, err := os.Stat(archived); os.IsNotExist(err) {
+		return archived, nil
+	}
+	base := trimCSVExtension(name)
+	archived = filepath.Join(archive, base+"_"+now.Format("20060102-150405")+strings.TrimPrefix(name, base))
+	if _, err := os.Stat(archived); !os.IsNotExist(err) {
+		return "", fmt.Errorf("%s is already archived", archived)
+	}
+	return archived, nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_watcher_poll(t *testing.T) {
+	in, out, archive := t.TempDir(), t.TempDir(), t.TempDir()
+	outputDir = out
+	defer func() { outputDir = "" }()
+
+	input := filepath.Join(in, "data.csv")
+	if err := os.WriteFile(input, []byte("id\n1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(out, "data.xlsx")
+	w := newWatcher(in, archive, ',')
+
+	// The first poll only records the file, as it might still be written to.
+	if err := w.poll(); err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Fatalf("poll() converted %s before its size was stable", input)
+	}
+
+	// The file grows between polls, so it is still not converted.
+	if err := os.WriteFile(input, []byte("id\n1\n2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.poll(); err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Fatalf("poll() converted %s while it was changing", input)
+	}
+
+	// Once unchanged, the file is converted and archived.
+	if err := w.poll(); err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Fatalf("poll() did not convert %s: %v", input, err)
+	}
+	if _, err := os.Stat(filepath.Join(archive, "data.csv")); err != nil {
+		t.Errorf("poll() did not archive %s: %v", input, err)
+	}
+	if _, err := os.Stat(input); !os.IsNotExist(err) {
+		t.Errorf("poll() left %s in the watched folder", input)
+	}
+}
+
+func Test_watcher_poll_Failed(t *testing.T) {
+	in := t.TempDir()
+	input := filepath.Join(in, "bad.csv")
+	if err := os.WriteFile(input, []byte("a,b\n1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w := newWatcher(in, "", ',')
+	for range 3 {
+		if err := w.poll(); err != nil {
+			t.Fatalf("poll() error = %v", err)
+		}
+	}
+	if _, ok := w.failed[input]; !ok {
+		t.Errorf("poll() expected %s to be recorded as failed", input)
+	}
+	if _, err := os.Stat(filepath.Join(in, "bad.xlsx")); !os.IsNotExist(err) {
+		t.Errorf("poll() wrote an Excel file for a file that failed to parse")
+	}
+}
+
+func Test_archivePath(t *testing.T) {
+	archive := t.TempDir()
+	now := time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC)
+	source := filepath.Join("in", "data.csv.gz")
+
+	got, err := archivePath(archive, source, now)
+	if expected := filepath.Join(archive, "data.csv.gz"); err != nil || got != expected {
+		t.Fatalf("archivePath() = %v, %v, expected %v", got, err, expected)
+	}
+	if err := os.WriteFile(got, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = archivePath(archive, source, now)
+	if expected := filepath.Join(archive, "data_20240131-090000.csv.gz"); err != nil || got != expected {
+		t.Fatalf("archivePath() = %v, %v, expected %v", got, err, expected)
+	}
+	if err := os.WriteFile(got, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := archivePath(archive, source, now); err == nil {
+		t.Errorf("archivePath() = %v, expected an error as both names are archived", got)
+	}
+}
@@ -0,0 +1,189 @@
+package file
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// chartTypes maps the supported chart types to their excelize chart type.
+var chartTypes = map[string]excelize.ChartType{
+	"line":    excelize.Line,
+	"bar":     excelize.Bar,
+	"column":  excelize.Col,
+	"pie":     excelize.Pie,
+	"scatter": excelize.Scatter,
+}
+
+// chartHeight is the number of rows taken up by a chart anchored next to the data.
+const chartHeight = 16
+
+// Chart describes a native Excel chart of the data written by SaveAsExcel.
+type Chart struct {
+	// Type is the chart type: line, bar, column, pie or scatter.
+	Type string
+	// X is the name of the column holding the categories, or the x values of a scatter chart.
+	X string
+	// Y are the names of the columns plotted as series.
+	Y []string
+	// Title is the title of the chart, defaults to the Y columns by the X column.
+	Title string
+	// Sheet is the name of the chart sheet, defaults to Chart, Chart2 and so on.
+	Sheet string
+	// Place is where the chart is added: "sheet" for a chart sheet of its own, or "data"
+	// to anchor it to the right of the data.
+	Place string
+}
+
+// ParseChart parses a chart specification such as "type=line,x=date,y=revenue,cost".
+// The keys are type, x, y, title, sheet and place. Lists continue until the next key.
+func ParseChart(spec string) (Chart, error) {
+	keys, err := parseSpec(spec, "type", "x", "y", "title", "sheet", "place")
+	if err != nil {
+		return Chart{}, err
+	}
+	last := func(key string) string {
+		if values := keys[key]; len(values) > 0 {
+			return values[len(values)-1]
+		}
+		return ""
+	}
+	c := Chart{
+		Type:  strings.ToLower(last("type")),
+		X:     last("x"),
+		Y:     keys["y"],
+		Title: last("title"),
+		Sheet: last("sheet"),
+		Place: strings.ToLower(last("place")),
+	}
+	if c.Type == "" {
+		c.Type = "line"
+	}
+	if _, ok := chartTypes[c.Type]; !ok {
+		return Chart{}, fmt.Errorf("unsupported chart type %q, expected line, bar, column, pie or scatter", c.Type)
+	}
+	if c.Place == "" {
+		c.Place = "sheet"
+	}
+	if c.Place != "sheet" && c.Place != "data" {
+		return Chart{}, fmt.Errorf("invalid chart place %q, expected sheet or data", c.Place)
+	}
+	if c.X == "" || len(c.Y) == 0 {
+		return Chart{}, fmt.Errorf("chart %q needs an x and at least one y column", spec)
+	}
+	return c, nil
+}
+
+// Check returns an error if the chart uses a column that is not among columns.
+func (c Chart) Check(columns []string) error {
+	for _, name := range append([]string{c.X}, c.Y...) {
+		if !slices.Contains(columns, name) {
+			return fmt.Errorf("chart column %q not found", name)
+		}
+	}
+	return nil
+}
+
+// WithCharts adds each of the charts to the workbook.
+func WithCharts(charts ...Chart) ExcelOption {
+	return func(o *excelOptions) {
+		o.charts = append(o.charts, charts...)
+	}
+}
+
+// writeCharts adds the charts, plotting the records written to the data sheet. Charts placed next
+// to the data are stacked to the right of the last column.
+func (c *CSV) writeCharts(f *excelize.File, sheetName string, charts []Chart, columns []string) error {
+	if len(charts) == 0 {
+		return nil
+	}
+	if len(c.Records) == 0 {
+		return fmt.Errorf("cannot add a chart without any rows")
+	}
+	lastRow := len(c.Records) + 1
+	// columnRange returns the reference to the rows first to last of the named column.
+	columnRange := func(name string, first, last int) (string, error) {
+		letters, err := excelize.ColumnNumberToName(slices.Index(columns, name) + 1)
+		if err != nil {
+			return "", err
+		}
+		if first == last {
+			return fmt.Sprintf("%s!$%s$%d", quoteSheetName(sheetName), letters, first), nil
+		}
+		return fmt.Sprintf("%s!$%s$%d:$%s$%d", quoteSheetName(sheetName), letters, first, letters, last), nil
+	}
+
+	sheets, anchored := 0, 0
+	for _, chart := range charts {
+		if err := chart.Check(columns); err != nil {
+			return err
+		}
+		categories, err := columnRange(chart.X, 2, lastRow)
+		if err != nil {
+			return err
+		}
+		title := chart.Title
+		if title == "" {
+			title = joinNames(chart.Y) + " by " + chart.X
+		}
+		options := &excelize.Chart{
+			Type:   chartTypes[chart.Type],
+			Title:  []excelize.RichTextRun{{Text: title}},
+			Legend: excelize.ChartLegend{Position: "bottom"},
+		}
+		if chart.Type != "pie" {
+			options.XAxis.Title = []excelize.RichTextRun{{Text: chart.X}}
+			options.YAxis.Title = []excelize.RichTextRun{{Text: joinNames(chart.Y)}}
+			options.YAxis.MajorGridLines = true
+		}
+		for _, name := range chart.Y {
+			values, err := columnRange(name, 2, lastRow)
+			if err != nil {
+				return err
+			}
+			header, err := columnRange(name, 1, 1)
+			if err != nil {
+				return err
+			}
+			options.Series = append(options.Series, excelize.ChartSeries{
+				Name:       header,
+				Categories: categories,
+				Values:     values,
+			})
+		}
+
+		if chart.Place == "data" {
+			cell, err := excelize.CoordinatesToCellName(len(columns)+2, 1+anchored*chartHeight)
+			if err != nil {
+				return err
+			}
+			anchored++
+			if err := f.AddChart(sheetName, cell, options); err != nil {
+				return fmt.Errorf("failed to add chart %s: %w", title, err)
+			}
+			continue
+		}
+		sheet := chart.Sheet
+		if sheet == "" {
+			sheet = "Chart"
+			if sheets > 0 {
+				sheet = fmt.Sprintf("Chart%d", sheets+1)
+			}
+		}
+		sheets++
+		if err := f.AddChartSheet(sheet, options); err != nil {
+			return fmt.Errorf("failed to add chart sheet %s: %w", sheet, err)
+		}
+	}
+	return nil
+}
+
+// joinNames joins column names for use in a title, e.g. "revenue, cost and profit".
+func joinNames(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
@@ -0,0 +1,94 @@
+package file
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func Test_ParseChart(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected Chart
+		wantErr  bool
+	}{
+		{
+			spec:     "type=line,x=date,y=revenue,cost",
+			expected: Chart{Type: "line", X: "date", Y: []string{"revenue", "cost"}, Place: "sheet"},
+		},
+		{
+			spec:     "x=region,y=amount,title=Sales,place=data",
+			expected: Chart{Type: "line", X: "region", Y: []string{"amount"}, Title: "Sales", Place: "data"},
+		},
+		{
+			spec:     "type=Pie,x=region,y=amount,sheet=Share",
+			expected: Chart{Type: "pie", X: "region", Y: []string{"amount"}, Sheet: "Share", Place: "sheet"},
+		},
+		{spec: "type=area,x=date,y=revenue", wantErr: true},
+		{spec: "type=line,x=date", wantErr: true},
+		{spec: "x=date,y=revenue,place=top", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseChart(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChart() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseChart() = %+v, expected %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_joinNames(t *testing.T) {
+	tests := []struct {
+		names    []string
+		expected string
+	}{
+		{names: []string{"revenue"}, expected: "revenue"},
+		{names: []string{"revenue", "cost"}, expected: "revenue and cost"},
+		{names: []string{"revenue", "cost", "profit"}, expected: "revenue, cost and profit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := joinNames(tt.names); got != tt.expected {
+				t.Errorf("joinNames() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_CSV_SaveAsExcel_Charts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xlsx")
+	c := New(
+		WithHeaders([]Column{{Name: "date", Type: StringType}, {Name: "revenue", Type: FloatType}, {Name: "cost", Type: FloatType}}),
+		WithRecords([][]Value{{"Jan", 10.0, 5.0}, {"Feb", 20.0, 8.0}}),
+	)
+	charts := []Chart{
+		{Type: "line", X: "date", Y: []string{"revenue", "cost"}, Place: "sheet"},
+		{Type: "column", X: "date", Y: []string{"revenue"}, Place: "data"},
+	}
+	if err := c.SaveAsExcel(path, "", WithCharts(charts...)); err != nil {
+		t.Fatalf("SaveAsExcel() error = %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	defer f.Close()
+	if sheets := f.GetSheetList(); !reflect.DeepEqual(sheets, []string{"Sheet1", "Chart"}) {
+		t.Errorf("SaveAsExcel() sheets = %v, expected [Sheet1 Chart]", sheets)
+	}
+	if active := f.GetSheetName(f.GetActiveSheetIndex()); active != "Sheet1" {
+		t.Errorf("SaveAsExcel() active sheet = %s, expected Sheet1", active)
+	}
+
+	unknown := Chart{Type: "line", X: "date", Y: []string{"profit"}, Place: "sheet"}
+	if err := c.SaveAsExcel(path, "", WithCharts(unknown)); err == nil {
+		t.Errorf("SaveAsExcel() expected error for unknown chart column")
+	}
+}
@@ -0,0 +1,162 @@
+package file
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SelectColumns keeps only the columns matched by the given selectors, in the order
+// the selectors are given. A selector is either a column name, a 1-based column index
+// or a regular expression enclosed in slashes (e.g. "/^amount_/").
+// Returns an error if a selector does not match any column.
+func (c *CSV) SelectColumns(selectors ...string) error {
+	var indexes []int
+	selected := make(map[int]bool)
+	for _, selector := range selectors {
+		matches, err := c.resolveSelector(selector)
+		if err != nil {
+			return err
+		}
+		for _, index := range matches {
+			if !selected[index] {
+				selected[index] = true
+				indexes = append(indexes, index)
+			}
+		}
+	}
+	c.project(indexes)
+	return nil
+}
+
+// ExcludeColumns removes the columns matched by the given selectors.
+// Selectors follow the same rules as in SelectColumns.
+// Returns an error if a selector does not match any column.
+func (c *CSV) ExcludeColumns(selectors ...string) error {
+	excluded := make(map[int]bool)
+	for _, selector := range selectors {
+		matches, err := c.resolveSelector(selector)
+		if err != nil {
+			return err
+		}
+		for _, index := range matches {
+			excluded[index] = true
+		}
+	}
+	indexes := make([]int, 0, len(c.Headers))
+	for i := range c.Headers {
+		if !excluded[i] {
+			indexes = append(indexes, i)
+		}
+	}
+	c.project(indexes)
+	return nil
+}
+
+// RenameColumns renames columns using a map of selector to new name.
+// Each selector must match exactly one column. All selectors are resolved against the
+// original names before any column is renamed, so columns can swap names, and an error
+// is returned if a column would get the same name as another column.
+func (c *CSV) RenameColumns(renames map[string]string) error {
+	selectors := make([]string, 0, len(renames))
+	for selector := range renames {
+		selectors = append(selectors, selector)
+	}
+	sort.Strings(selectors)
+
+	names := c.GetHeaderNames()
+	indexes := make([]int, len(selectors))
+	renamed := make(map[int]string, len(selectors))
+	for i, selector := range selectors {
+		index, err := c.ColumnIndex(selector)
+		if err != nil {
+			return err
+		}
+		if previous, ok := renamed[index]; ok {
+			return fmt.Errorf("column selectors %q and %q both rename column %q", previous, selector, c.Headers[index].Name)
+		}
+		indexes[i] = index
+		renamed[index] = selector
+		names[index] = renames[selector]
+	}
+	for i, index := range indexes {
+		for j, name := range names {
+			if j != index && name == names[index] {
+				return fmt.Errorf("renaming column selector %q to %q would give two columns the same name", selectors[i], name)
+			}
+		}
+	}
+
+	for _, index := range indexes {
+		c.Headers[index].Name = names[index]
+	}
+	return nil
+}
+
+// ColumnIndex returns the index of the column matched by the selector.
+// Returns an error if the selector does not match exactly one column.
+func (c *CSV) ColumnIndex(selector string) (int, error) {
+	matches, err := c.resolveSelector(selector)
+	if err != nil {
+		return -1, err
+	}
+	if len(matches) > 1 {
+		return -1, fmt.Errorf("column selector %q matches %d columns, expected exactly one", selector, len(matches))
+	}
+	return matches[0], nil
+}
+
+// resolveSelector returns the indexes of the columns matched by the selector.
+func (c *CSV) resolveSelector(selector string) ([]int, error) {
+	if len(selector) > 1 && strings.HasPrefix(selector, "/") && strings.HasSuffix(selector, "/") {
+		re, err := regexp.Compile(selector[1 : len(selector)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid column pattern %q: %w", selector, err)
+		}
+		var matches []int
+		for i, column := range c.Headers {
+			if re.MatchString(column.Name) {
+				matches = append(matches, i)
+			}
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no columns match pattern %q", selector)
+		}
+		return matches, nil
+	}
+
+	for i, column := range c.Headers {
+		if column.Name == selector {
+			return []int{i}, nil
+		}
+	}
+
+	if index, err := strconv.Atoi(selector); err == nil {
+		if index < 1 || index > len(c.Headers) {
+			return nil, fmt.Errorf("column index %d out of range, expected 1-%d", index, len(c.Headers))
+		}
+		return []int{index - 1}, nil
+	}
+	return nil, fmt.Errorf("column %q not found", selector)
+}
+
+// project rebuilds Headers and Records so that they only contain the columns at the given indexes.
+func (c *CSV) project(indexes []int) {
+	headers := make([]Column, len(indexes))
+	for i, index := range indexes {
+		headers[i] = c.Headers[index]
+	}
+	c.Headers = headers
+
+	for r, record := range c.Records {
+		projected := make([]Value, len(indexes))
+		for i, index := range indexes {
+			if index < len(record) {
+				projected[i] = record[index]
+			}
+		}
+		c.Records[r] = projected
+	}
+}
@@ -0,0 +1,161 @@
+package file
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newColumnsTestCSV() *CSV {
+	return &CSV{
+		Headers: []Column{
+			{Name: "id", Type: StringType},
+			{Name: "amount_net", Type: StringType},
+			{Name: "amount_gross", Type: StringType},
+			{Name: "country", Type: StringType},
+		},
+		Records: [][]Value{
+			{"1", "10", "12", "SE"},
+			{"2", "20", "24", "NO"},
+		},
+	}
+}
+
+func Test_CSV_SelectColumns(t *testing.T) {
+	tests := []struct {
+		name            string
+		selectors       []string
+		expectedHeaders []string
+		expected        [][]Value
+		wantErr         bool
+	}{
+		{
+			name:            "Select by name",
+			selectors:       []string{"country", "id"},
+			expectedHeaders: []string{"country", "id"},
+			expected: [][]Value{
+				{"SE", "1"},
+				{"NO", "2"},
+			},
+		},
+		{
+			name:            "Select by index",
+			selectors:       []string{"2", "1"},
+			expectedHeaders: []string{"amount_net", "id"},
+			expected: [][]Value{
+				{"10", "1"},
+				{"20", "2"},
+			},
+		},
+		{
+			name:            "Select by regex without duplicates",
+			selectors:       []string{"amount_gross", "/^amount_/"},
+			expectedHeaders: []string{"amount_gross", "amount_net"},
+			expected: [][]Value{
+				{"12", "10"},
+				{"24", "20"},
+			},
+		},
+		{
+			name:      "Unknown column",
+			selectors: []string{"missing"},
+			wantErr:   true,
+		},
+		{
+			name:      "Index out of range",
+			selectors: []string{"5"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newColumnsTestCSV()
+			err := c.SelectColumns(tt.selectors...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SelectColumns() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := c.GetHeaderNames(); !reflect.DeepEqual(got, tt.expectedHeaders) {
+				t.Errorf("SelectColumns() headers = %v, expected %v", got, tt.expectedHeaders)
+			}
+			if !reflect.DeepEqual(c.Records, tt.expected) {
+				t.Errorf("SelectColumns() records = %v, expected %v", c.Records, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_CSV_ExcludeColumns(t *testing.T) {
+	c := newColumnsTestCSV()
+	if err := c.ExcludeColumns("/^amount_/", "1"); err != nil {
+		t.Fatalf("ExcludeColumns() error = %v", err)
+	}
+	expectedHeaders := []string{"country"}
+	if got := c.GetHeaderNames(); !reflect.DeepEqual(got, expectedHeaders) {
+		t.Errorf("ExcludeColumns() headers = %v, expected %v", got, expectedHeaders)
+	}
+	expected := [][]Value{{"SE"}, {"NO"}}
+	if !reflect.DeepEqual(c.Records, expected) {
+		t.Errorf("ExcludeColumns() records = %v, expected %v", c.Records, expected)
+	}
+}
+
+func Test_CSV_RenameColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		renames  map[string]string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "Rename by name and index",
+			renames:  map[string]string{"id": "ID", "4": "Country"},
+			expected: []string{"ID", "amount_net", "amount_gross", "Country"},
+		},
+		{
+			name:     "Swap names",
+			renames:  map[string]string{"amount_net": "amount_gross", "amount_gross": "amount_net"},
+			expected: []string{"id", "amount_gross", "amount_net", "country"},
+		},
+		{
+			name:    "Rename to the same name",
+			renames: map[string]string{"amount_net": "amount", "amount_gross": "amount"},
+			wantErr: true,
+		},
+		{
+			name:    "Rename to an existing name",
+			renames: map[string]string{"id": "country"},
+			wantErr: true,
+		},
+		{
+			name:    "Rename a column twice",
+			renames: map[string]string{"id": "ID", "1": "Id"},
+			wantErr: true,
+		},
+		{
+			name:    "Rename ambiguous pattern",
+			renames: map[string]string{"/^amount_/": "amount"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newColumnsTestCSV()
+			err := c.RenameColumns(tt.renames)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RenameColumns() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := c.GetHeaderNames(); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("RenameColumns() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
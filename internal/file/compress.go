@@ -0,0 +1,118 @@
+package file
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	// gzipMagic is the header that identifies gzip compressed data.
+	gzipMagic = []byte{0x1f, 0x8b}
+	// bzip2Magic is the header that identifies bzip2 compressed data.
+	bzip2Magic = []byte("BZh")
+	// zstdMagic is the header that identifies zstd compressed data.
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// zipSeparator separates the path of a zip archive from the name of a file within it.
+const zipSeparator = ".zip/"
+
+// decompress returns a reader that transparently decompresses the data read from r
+// if it is gzip, bzip2 or zstd compressed, detected by its magic bytes.
+// Uncompressed data is returned as is.
+func decompress(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(header, bzip2Magic):
+		return io.NopCloser(bzip2.NewReader(br)), nil
+	case bytes.HasPrefix(header, zstdMagic):
+		decoder, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+	return io.NopCloser(br), nil
+}
+
+// openFile opens the file at the path for reading. A path on the form "archive.zip/name.csv"
+// refers to the file name.csv within the zip archive archive.zip.
+func openFile(path string) (io.ReadCloser, error) {
+	archive, member, ok := splitZipPath(path)
+	if !ok {
+		return os.Open(path)
+	}
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	f, err := r.Open(member)
+	if err != nil {
+		r.Close()
+		return nil, fmt.Errorf("failed to open %s in %s: %w", member, archive, err)
+	}
+	return &zipFile{ReadCloser: f, archive: r}, nil
+}
+
+// splitZipPath splits a path referring to a file within a zip archive into the archive path
+// and the name of the file. ok is false if the path does not refer to an existing zip archive.
+func splitZipPath(path string) (archive, member string, ok bool) {
+	index := strings.Index(path, zipSeparator)
+	if index < 0 {
+		return "", "", false
+	}
+	archive = path[:index+len(zipSeparator)-1]
+	if info, err := os.Stat(archive); err != nil || info.IsDir() {
+		return "", "", false
+	}
+	return archive, path[index+len(zipSeparator):], true
+}
+
+// zipFile is a file within a zip archive, closing the archive when the file is closed.
+type zipFile struct {
+	io.ReadCloser
+	archive *zip.ReadCloser
+}
+
+func (z *zipFile) Close() error {
+	err := z.ReadCloser.Close()
+	if closeErr := z.archive.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// ListZip returns the paths of the files within the zip archive, sorted by name, on the form
+// "archive.zip/name". The returned paths can be used as the FilePath of a CSV.
+func ListZip(path string) ([]string, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var files []string
+	for _, f := range r.File {
+		if !f.FileInfo().IsDir() {
+			files = append(files, path+"/"+f.Name)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,104 @@
+package file
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func Test_decompress(t *testing.T) {
+	const content = "a,b\n1,2\n"
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Write([]byte(content))
+	gw.Close()
+
+	var zstdCompressed bytes.Buffer
+	zw, err := zstd.NewWriter(&zstdCompressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw.Write([]byte(content))
+	zw.Close()
+
+	// bzip2 compressed content, as the standard library has no bzip2 writer.
+	bzip2Compressed := []byte{
+		0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0xbf, 0x87, 0x40, 0x7f, 0x00, 0x00,
+		0x03, 0x59, 0x00, 0x00, 0x10, 0x00, 0x04, 0x30, 0x00, 0x30, 0x00, 0x20, 0x00, 0x30, 0xc0, 0x08,
+		0x69, 0xb2, 0x88, 0x23, 0x27, 0x8b, 0xb9, 0x22, 0x9c, 0x28, 0x48, 0x5f, 0xc3, 0xa0, 0x3f, 0x80,
+	}
+
+	tests := []struct {
+		name     string
+		input    []byte
+		expected string
+	}{
+		{name: "Plain text", input: []byte(content), expected: content},
+		{name: "Gzip", input: gzipped.Bytes(), expected: content},
+		{name: "Bzip2", input: bzip2Compressed, expected: content},
+		{name: "Zstd", input: zstdCompressed.Bytes(), expected: content},
+		{name: "Empty", input: []byte{}, expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := decompress(bytes.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("decompress() error = %v", err)
+			}
+			defer r.Close()
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("decompress() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_ListZip(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "export.zip")
+	out, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(out)
+	for name, content := range map[string]string{
+		"b.csv":     "id\n2\n",
+		"dir/a.csv": "id\n1\n",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	out.Close()
+
+	files, err := ListZip(archive)
+	if err != nil {
+		t.Fatalf("ListZip() error = %v", err)
+	}
+	expected := []string{archive + "/b.csv", archive + "/dir/a.csv"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("ListZip() = %v, expected %v", files, expected)
+	}
+
+	c := New(WithFilePath(files[1]), WithDelimiter(','))
+	if err := c.Read(); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !reflect.DeepEqual(c.Records, [][]Value{{"1"}}) {
+		t.Errorf("Read() = %v, expected %v", c.Records, [][]Value{{"1"}})
+	}
+}
@@ -0,0 +1,122 @@
+package file
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ConditionalFormat is a conditional formatting rule applied to the rows of a column.
+type ConditionalFormat struct {
+	// Column is the name of the formatted column.
+	Column string
+	// Rule is the kind of formatting: color-scale, data-bar, above, below or duplicates.
+	Rule string
+	// Threshold is the number compared against by the above and below rules.
+	Threshold float64
+}
+
+// ParseConditionalFormat parses a conditional format of the form "column=rule". The rules are
+// color-scale, data-bar, duplicates, above(threshold) and below(threshold), e.g. "amount=above(1000)".
+func ParseConditionalFormat(spec string) (ConditionalFormat, error) {
+	column, rule, ok := strings.Cut(spec, "=")
+	column, rule = strings.TrimSpace(column), strings.ToLower(strings.TrimSpace(rule))
+	if !ok || column == "" || rule == "" {
+		return ConditionalFormat{}, fmt.Errorf("invalid conditional format %q, expected column=rule", spec)
+	}
+	cf := ConditionalFormat{Column: column, Rule: rule}
+	if open := strings.Index(rule, "("); open >= 0 && strings.HasSuffix(rule, ")") {
+		cf.Rule = strings.TrimSpace(rule[:open])
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(rule[open+1:len(rule)-1]), 64)
+		if err != nil {
+			return ConditionalFormat{}, fmt.Errorf("invalid threshold in conditional format %q", spec)
+		}
+		cf.Threshold = threshold
+		if cf.Rule != "above" && cf.Rule != "below" {
+			return ConditionalFormat{}, fmt.Errorf("conditional format rule %q does not take a threshold", cf.Rule)
+		}
+		return cf, nil
+	}
+	switch cf.Rule {
+	case "color-scale", "data-bar", "duplicates":
+		return cf, nil
+	case "above", "below":
+		return ConditionalFormat{}, fmt.Errorf("conditional format rule %s needs a threshold, e.g. %s(100)", cf.Rule, cf.Rule)
+	}
+	return ConditionalFormat{}, fmt.Errorf("unsupported conditional format rule %q, expected color-scale, data-bar, duplicates, above or below", cf.Rule)
+}
+
+// Check returns an error if the column is not among columns.
+func (cf ConditionalFormat) Check(columns []string) error {
+	if !slices.Contains(columns, cf.Column) {
+		return fmt.Errorf("conditional format column %q not found", cf.Column)
+	}
+	return nil
+}
+
+// WithConditionalFormats applies the conditional formats to the rows of their columns.
+func WithConditionalFormats(formats ...ConditionalFormat) ExcelOption {
+	return func(o *excelOptions) {
+		o.conditionalFormats = append(o.conditionalFormats, formats...)
+	}
+}
+
+// writeConditionalFormats applies the conditional formats to the given number of data rows on the sheet.
+// Cells are highlighted with a light red fill and dark red text, like the Excel default.
+func (c *CSV) writeConditionalFormats(f *excelize.File, sheetName string, formats []ConditionalFormat, columns []string, rows int) error {
+	if len(formats) == 0 || rows == 0 {
+		return nil
+	}
+	highlight, err := f.NewConditionalStyle(&excelize.Style{
+		Font: &excelize.Font{Color: "9C0006"},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"FFC7CE"}, Pattern: 1},
+	})
+	if err != nil {
+		return err
+	}
+	for _, cf := range formats {
+		if err := cf.Check(columns); err != nil {
+			return err
+		}
+		letters, err := excelize.ColumnNumberToName(slices.Index(columns, cf.Column) + 1)
+		if err != nil {
+			return err
+		}
+		cellRange := fmt.Sprintf("%s2:%s%d", letters, letters, rows+1)
+
+		var opts excelize.ConditionalFormatOptions
+		switch cf.Rule {
+		case "color-scale":
+			opts = excelize.ConditionalFormatOptions{
+				Type: "3_color_scale", Criteria: "=",
+				MinType: "min", MidType: "percentile", MidValue: "50", MaxType: "max",
+				MinColor: "#F8696B", MidColor: "#FFEB84", MaxColor: "#63BE7B",
+			}
+		case "data-bar":
+			opts = excelize.ConditionalFormatOptions{Type: "data_bar", Criteria: "=", MinType: "min", MaxType: "max", BarColor: "#638EC6"}
+		case "duplicates":
+			opts = excelize.ConditionalFormatOptions{Type: "duplicate", Criteria: "=", Format: &highlight}
+		case "above", "below":
+			// A cell rule treats blank cells as 0, which would highlight missing values and
+			// the blank rows for data entry, so blank cells are excluded with a formula rule.
+			operator := ">"
+			if cf.Rule == "below" {
+				operator = "<"
+			}
+			cell := fmt.Sprintf("%s2", letters)
+			opts = excelize.ConditionalFormatOptions{
+				Type: "formula", Format: &highlight,
+				Criteria: fmt.Sprintf(`AND(%s<>"",%s%s%s)`, cell, cell, operator, strconv.FormatFloat(cf.Threshold, 'f', -1, 64)),
+			}
+		default:
+			return fmt.Errorf("unsupported conditional format rule %q", cf.Rule)
+		}
+		if err := f.SetConditionalFormat(sheetName, cellRange, []excelize.ConditionalFormatOptions{opts}); err != nil {
+			return fmt.Errorf("failed to format column %s: %w", cf.Column, err)
+		}
+	}
+	return nil
+}
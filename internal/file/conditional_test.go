@@ -0,0 +1,69 @@
+package file
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func Test_ParseConditionalFormat(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected ConditionalFormat
+		wantErr  bool
+	}{
+		{spec: "amount=color-scale", expected: ConditionalFormat{Column: "amount", Rule: "color-scale"}},
+		{spec: "amount = Data-Bar", expected: ConditionalFormat{Column: "amount", Rule: "data-bar"}},
+		{spec: "order id=duplicates", expected: ConditionalFormat{Column: "order id", Rule: "duplicates"}},
+		{spec: "amount=above(1000)", expected: ConditionalFormat{Column: "amount", Rule: "above", Threshold: 1000}},
+		{spec: "balance=below(-0.5)", expected: ConditionalFormat{Column: "balance", Rule: "below", Threshold: -0.5}},
+		{spec: "amount", wantErr: true},
+		{spec: "amount=above", wantErr: true},
+		{spec: "amount=above(many)", wantErr: true},
+		{spec: "amount=duplicates(2)", wantErr: true},
+		{spec: "amount=icons", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseConditionalFormat(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseConditionalFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.expected {
+				t.Errorf("ParseConditionalFormat() = %+v, expected %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_CSV_SaveAsExcel_ConditionalFormats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xlsx")
+	c := New(
+		WithHeaders([]Column{{Name: "id", Type: StringType}, {Name: "amount", Type: FloatType}}),
+		WithRecords([][]Value{{"a", 10.0}, {"a", 2000.0}, {"b", 30.0}}),
+	)
+	formats := []ConditionalFormat{
+		{Column: "id", Rule: "duplicates"},
+		{Column: "amount", Rule: "above", Threshold: 1000},
+	}
+	if err := c.SaveAsExcel(path, "", WithConditionalFormats(formats...), WithBlankRows(2)); err != nil {
+		t.Fatalf("SaveAsExcel() error = %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	defer f.Close()
+	got, err := f.GetConditionalFormats("Sheet1")
+	if err != nil {
+		t.Fatalf("GetConditionalFormats() error = %v", err)
+	}
+	if opts := got["A2:A6"]; len(opts) != 1 || opts[0].Type != "duplicate" {
+		t.Errorf("SaveAsExcel() conditional format of A2:A6 = %+v, expected duplicate", opts)
+	}
+	if opts := got["B2:B6"]; len(opts) != 1 || opts[0].Type != "formula" || opts[0].Criteria != `AND(B2<>"",B2>1000)` {
+		t.Errorf("SaveAsExcel() conditional format of B2:B6 = %+v, expected a formula excluding blank cells", opts)
+	}
+}
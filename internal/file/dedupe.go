@@ -0,0 +1,54 @@
+package file
+
+import (
+	"slices"
+	"strings"
+)
+
+// keyIndexes resolves the key selectors to column indexes.
+func (c *CSV) keyIndexes(keys []string) ([]int, error) {
+	var indexes []int
+	for _, key := range keys {
+		matches, err := c.resolveSelector(key)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, matches...)
+	}
+	return indexes, nil
+}
+
+// dedupe removes records with duplicate values at the given indexes and returns the number of records removed.
+func (c *CSV) dedupe(indexes []int, keepLast bool) int {
+	records := slices.Clone(c.Records)
+	if keepLast {
+		slices.Reverse(records)
+	}
+	seen := make(map[string]bool, len(records))
+	deduped := make([][]Value, 0, len(records))
+	for _, record := range records {
+		key := recordKey(record, indexes)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, record)
+	}
+	if keepLast {
+		slices.Reverse(deduped)
+	}
+
+	dropped := len(c.Records) - len(deduped)
+	c.Records = deduped
+	return dropped
+}
+
+// recordKey builds a string identifying the values of the record at the given indexes.
+func recordKey(record []Value, indexes []int) string {
+	var sb strings.Builder
+	for _, index := range indexes {
+		sb.WriteString(FormatValue(valueAt(record, index)))
+		sb.WriteByte(0x1f)
+	}
+	return sb.String()
+}
@@ -0,0 +1,119 @@
+package file
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ListsSheet is the name of the hidden sheet holding dropdown values that do not fit in a validation rule.
+const ListsSheet = "Lists"
+
+// maxDropListLength is the maximum length of the values of a dropdown written directly in its validation rule.
+const maxDropListLength = 255
+
+// LowCardinalityColumns returns the names of the string columns with at most maxValues distinct
+// values, where values repeat, so they are suitable as dropdown lists.
+func (c *CSV) LowCardinalityColumns(maxValues int) []string {
+	var columns []string
+	for _, s := range c.Summarize() {
+		if s.Type != StringType || s.Distinct == 0 || s.Distinct > maxValues || s.Distinct == s.Count {
+			continue
+		}
+		columns = append(columns, s.Name)
+	}
+	return columns
+}
+
+// WithDropdowns restricts the cells of the columns to the values found in them, with a dropdown list.
+func WithDropdowns(columns ...string) ExcelOption {
+	return func(o *excelOptions) {
+		o.dropdowns = append(o.dropdowns, columns...)
+	}
+}
+
+// CheckDropdowns returns an error if a dropdown column does not exist.
+func (c *CSV) CheckDropdowns(columns ...string) error {
+	for _, name := range columns {
+		if !slices.Contains(c.GetHeaderNames(), name) {
+			return fmt.Errorf("dropdown column %q not found", name)
+		}
+	}
+	return nil
+}
+
+// dropdownValues returns the sorted distinct values of the column that are not empty.
+func (c *CSV) dropdownValues(index int) []string {
+	seen := make(map[string]bool)
+	var values []string
+	for _, record := range c.Records {
+		value := valueAt(record, index)
+		if isNull(value) {
+			continue
+		}
+		if text := FormatValue(value); !seen[text] {
+			seen[text] = true
+			values = append(values, text)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// writeDropdowns adds a data validation list to the given number of data rows of each dropdown column.
+// Values are written in the validation rule if they fit, and otherwise to a column of the hidden lists sheet.
+func (c *CSV) writeDropdowns(f *excelize.File, sheetName string, columns []string, rows int) error {
+	if len(columns) == 0 || rows == 0 {
+		return nil
+	}
+	if err := c.CheckDropdowns(columns...); err != nil {
+		return err
+	}
+	listColumns := 0
+	for _, name := range columns {
+		index := slices.Index(c.GetHeaderNames(), name)
+		values := c.dropdownValues(index)
+		if len(values) == 0 {
+			continue
+		}
+		letters, err := excelize.ColumnNumberToName(index + 1)
+		if err != nil {
+			return err
+		}
+		dv := excelize.NewDataValidation(true)
+		dv.SetSqref(fmt.Sprintf("%s2:%s%d", letters, letters, rows+1))
+		dv.SetError(excelize.DataValidationErrorStyleStop, name, "Choose a value from the list")
+
+		hasComma := slices.ContainsFunc(values, func(v string) bool { return strings.Contains(v, ",") })
+		if len(strings.Join(values, ",")) <= maxDropListLength && !hasComma {
+			if err := dv.SetDropList(values); err != nil {
+				return err
+			}
+		} else {
+			if listColumns == 0 {
+				if _, err := f.NewSheet(ListsSheet); err != nil {
+					return err
+				}
+				if err := f.SetSheetVisible(ListsSheet, false); err != nil {
+					return err
+				}
+			}
+			listColumns++
+			listLetters, err := excelize.ColumnNumberToName(listColumns)
+			if err != nil {
+				return err
+			}
+			if err := f.SetSheetCol(ListsSheet, listLetters+"1", &values); err != nil {
+				return err
+			}
+			dv.SetSqrefDropList(fmt.Sprintf("%s!$%s$1:$%s$%d", ListsSheet, listLetters, listLetters, len(values)))
+		}
+		if err := f.AddDataValidation(sheetName, dv); err != nil {
+			return fmt.Errorf("failed to add dropdown to column %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package file
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func Test_CSV_LowCardinalityColumns(t *testing.T) {
+	c := &CSV{
+		Headers: []Column{
+			{Name: "id", Type: StringType},
+			{Name: "status", Type: StringType},
+			{Name: "amount", Type: FloatType},
+			{Name: "country", Type: StringType},
+		},
+		Records: [][]Value{
+			{"1", "open", 1.0, "SE"},
+			{"2", "closed", 1.0, "NO"},
+			{"3", "open", 2.0, "DK"},
+			{"4", "", 3.0, "FI"},
+		},
+	}
+	tests := []struct {
+		maxValues int
+		expected  []string
+	}{
+		{maxValues: 10, expected: []string{"status"}},
+		{maxValues: 1, expected: nil},
+	}
+	for _, tt := range tests {
+		if got := c.LowCardinalityColumns(tt.maxValues); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("LowCardinalityColumns(%d) = %v, expected %v", tt.maxValues, got, tt.expected)
+		}
+	}
+}
+
+func Test_CSV_SaveAsExcel_Dropdowns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xlsx")
+	long := strings.Repeat("x", 200)
+	c := New(
+		WithHeaders([]Column{{Name: "status", Type: StringType}, {Name: "note", Type: StringType}, {Name: "qty", Type: IntegerType}}),
+		WithRecords([][]Value{{"open", long + "a", int64(1)}, {"closed", long + "b", int64(2)}, {"open", "", int64(3)}}),
+	)
+	options := []ExcelOption{
+		WithDropdowns("status", "note"),
+		WithBlankRows(5),
+		WithFormulas(Formula{Name: "double", Expression: "[qty]*2"}),
+		WithTotals("sum"),
+	}
+	if err := c.SaveAsExcel(path, "", options...); err != nil {
+		t.Fatalf("SaveAsExcel() error = %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	defer f.Close()
+	validations, err := f.GetDataValidations("Sheet1")
+	if err != nil {
+		t.Fatalf("GetDataValidations() error = %v", err)
+	}
+	if len(validations) != 2 {
+		t.Fatalf("SaveAsExcel() added %d data validations, expected 2", len(validations))
+	}
+	if validations[0].Sqref != "A2:A9" || validations[0].Formula1 != `"closed,open"` {
+		t.Errorf("SaveAsExcel() status validation = %s %s, expected A2:A9 \"closed,open\"", validations[0].Sqref, validations[0].Formula1)
+	}
+	if validations[1].Sqref != "B2:B9" || validations[1].Formula1 != "Lists!$A$1:$A$2" {
+		t.Errorf("SaveAsExcel() note validation = %s %s, expected B2:B9 Lists!$A$1:$A$2", validations[1].Sqref, validations[1].Formula1)
+	}
+	if visible, _ := f.GetSheetVisible(ListsSheet); visible {
+		t.Errorf("SaveAsExcel() lists sheet is visible")
+	}
+	if formula, _ := f.GetCellFormula("Sheet1", "D9"); formula != "C9*2" {
+		t.Errorf("SaveAsExcel() formula in blank row = %q, expected C9*2", formula)
+	}
+	if formula, _ := f.GetCellFormula("Sheet1", "C10"); formula != "SUM(C2:C9)" {
+		t.Errorf("SaveAsExcel() totals = %q, expected SUM(C2:C9)", formula)
+	}
+}
@@ -4,10 +4,16 @@ package file
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"math/rand/v2"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/xuri/excelize/v2"
 )
@@ -20,8 +26,47 @@ const (
 	StringType ColumnType = iota + 1
 	FloatType
 	IntegerType
+	DateType
+	HyperlinkType
+	EmailType
 )
 
+// String returns the name of the column type.
+func (t ColumnType) String() string {
+	switch t {
+	case StringType:
+		return "string"
+	case FloatType:
+		return "float"
+	case IntegerType:
+		return "integer"
+	case DateType:
+		return "date"
+	case HyperlinkType:
+		return "hyperlink"
+	case EmailType:
+		return "email"
+	}
+	return fmt.Sprintf("ColumnType(%d)", int(t))
+}
+
+// dateLayouts lists the layouts, in order of preference, that are recognised as dates.
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+}
+
+// parseDate attempts to parse the value using the layouts in dateLayouts.
+func parseDate(value string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
 // Column represents a column in the CSV file, including its name and inferred data type.
 type Column struct {
 	// Name is the name of the column, typically read from the header row.
@@ -45,6 +90,12 @@ type CSV struct {
 	// Records is a slice of slices, where each inner slice represents a row of data.
 	// The data type of the elements within the inner slices can vary based on type inference.
 	Records [][]Value
+	// LineNumbers holds the line in the source file on which each record starts, as set by Read.
+	LineNumbers []int
+	// SkipBadRows makes Read skip malformed rows instead of failing.
+	SkipBadRows bool
+	// RejectedRows is the number of malformed rows skipped by Read.
+	RejectedRows int
 }
 
 // New creates a new CSV struct with the specified options.
@@ -70,6 +121,14 @@ func WithDelimiter(delimiter rune) func(*CSV) {
 	}
 }
 
+// WithSkipBadRows sets whether Read skips malformed rows, such as rows with the wrong
+// number of fields, instead of failing.
+func WithSkipBadRows(skip bool) func(*CSV) {
+	return func(c *CSV) {
+		c.SkipBadRows = skip
+	}
+}
+
 // WithHeaders sets the column headers for the CSV struct.
 func WithHeaders(columns []Column) func(*CSV) {
 	return func(c *CSV) {
@@ -85,19 +144,27 @@ func WithRecords(records [][]Value) func(*CSV) {
 }
 
 // Read reads the CSV file, parses its contents, and populates the CSV struct.
+// Gzip, bzip2 and zstd compressed files are decompressed transparently, and a path on the
+// form "archive.zip/name.csv" reads name.csv from within the zip archive.
 // It infers column names from the first row and stores the data in the Records field.
 // Returns an error if the file cannot be opened or read.
 func (c *CSV) Read() error {
 	if c.FilePath == "" {
 		return fmt.Errorf("file path is empty, a valid file path is required")
 	}
-	file, err := os.Open(c.FilePath)
+	file, err := openFile(c.FilePath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	records, err := readCSV(file, c.Delimiter)
+	reader, err := decompress(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", c.FilePath, err)
+	}
+	defer reader.Close()
+
+	records, lines, rejected, err := readCSVLines(reader, c.Delimiter, c.SkipBadRows)
 	if err != nil {
 		return err
 	}
@@ -122,21 +189,46 @@ func (c *CSV) Read() error {
 				c.Records[i][j] = value
 			}
 		}
+		c.LineNumbers = lines[1:]
 	}
+	c.RejectedRows = rejected
 	return nil
 }
 
 func readCSV(reader io.Reader, delimiter rune) ([][]string, error) {
+	records, _, _, err := readCSVLines(reader, delimiter, false)
+	return records, err
+}
+
+// readCSVLines reads all records from the reader along with the line number on which each record starts.
+// If skipBadRows is true, malformed rows are skipped and counted instead of returning an error.
+func readCSVLines(reader io.Reader, delimiter rune, skipBadRows bool) ([][]string, []int, int, error) {
 	r := csv.NewReader(reader)
 	r.Comma = delimiter
-	records, err := r.ReadAll()
-	if err != nil {
-		return nil, err
+	var records [][]string
+	var lines []int
+	rejected := 0
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if err != nil && skipBadRows && errors.As(err, &parseErr) {
+			rejected++
+			continue
+		}
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		line, _ := r.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
 	}
-	return records, nil
+	return records, lines, rejected, nil
 }
 
-// ConvertColumnTypes attempts to convert string values in the Records to their inferred types (float or integer).
+// ConvertColumnTypes attempts to convert string values in the Records to their inferred types (float, integer or date).
 // This function relies on the inferColumnTypes method to determine the appropriate type for each column.
 func (c *CSV) ConvertColumnTypes() {
 	for _, record := range c.Records {
@@ -151,6 +243,10 @@ func (c *CSV) ConvertColumnTypes() {
 					if parsedValue, err := strconv.ParseInt(stringValue, 10, 64); err == nil {
 						record[i] = parsedValue
 					}
+				case DateType:
+					if parsedValue, ok := parseDate(stringValue); ok {
+						record[i] = parsedValue
+					}
 				}
 			}
 		}
@@ -158,33 +254,102 @@ func (c *CSV) ConvertColumnTypes() {
 }
 
 // inferColumnTypes analyzes a sample of rows to infer the data type of each column.
-// It checks if the values in a column can be parsed as float or integer.
+// It checks if the values in a column can be parsed as float, integer or date, or are hyperlinks or email addresses.
+// A column mixing integers and floats is a float column. Empty values are ignored, so a numeric
+// column with missing values is still numeric, while a column without any values is kept as a string column.
 // The number of rows to inspect is determined by the defaultTypeInferanceRows constant.
 func (c *CSV) InferColumnTypes() {
 	rangeToCheck := min(defaultTypeInferanceRows, len(c.Records))
 	for i := range c.Headers {
-		floatCount, intCount := 0, 0
+		floatCount, intCount, dateCount, linkCount, emailCount, valueCount := 0, 0, 0, 0, 0, 0
 		for _, record := range c.Records[:rangeToCheck] {
+			if isNull(valueAt(record, i)) {
+				continue
+			}
+			valueCount++
 			if stringValue, ok := record[i].(string); ok {
 				if _, err := strconv.ParseInt(stringValue, 10, 64); err == nil {
 					intCount++
 				} else if _, err := strconv.ParseFloat(stringValue, 64); err == nil {
 					floatCount++
+				} else if _, ok := parseDate(stringValue); ok {
+					dateCount++
+				} else if isHyperlink(stringValue) {
+					linkCount++
+				} else if isEmail(stringValue) {
+					emailCount++
 				}
 			}
 		}
-		if floatCount == rangeToCheck {
-			c.Headers[i].Type = FloatType
-		} else if intCount == rangeToCheck {
+		if valueCount == 0 {
+			continue
+		}
+		if intCount == valueCount {
 			c.Headers[i].Type = IntegerType
+		} else if floatCount+intCount == valueCount {
+			c.Headers[i].Type = FloatType
+		} else if dateCount == valueCount {
+			c.Headers[i].Type = DateType
+		} else if linkCount == valueCount {
+			c.Headers[i].Type = HyperlinkType
+		} else if emailCount == valueCount {
+			c.Headers[i].Type = EmailType
+		}
+	}
+}
+
+// ExcelOption configures the workbook written by SaveAsExcel.
+type ExcelOption func(*excelOptions)
+
+type excelOptions struct {
+	formulas []Formula
+	totals   []string
+	summary  bool
+	pivots   []Pivot
+	charts   []Chart
+
+	conditionalFormats []ConditionalFormat
+	dropdowns          []string
+	blankRows          int
+	linkText           map[string]string
+	properties         Properties
+}
+
+// WithBlankRows extends the formulas, conditional formats and dropdowns of the sheet over n blank rows
+// under the data, for data entry. Totals rows are written under the blank rows and include them.
+func WithBlankRows(n int) ExcelOption {
+	return func(o *excelOptions) {
+		o.blankRows = max(n, 0)
+	}
+}
+
+// excelRow returns the values of the record to write to a row of the sheet. Empty values are
+// written as blank cells rather than empty text, so formulas and totals treat them as missing.
+func excelRow(record []Value) []Value {
+	values := make([]Value, len(record))
+	for i, value := range record {
+		if !isNull(value) {
+			values[i] = value
 		}
 	}
+	return values
 }
 
 // SaveAsExcel saves the CSV data to an Excel file.
 // It creates a new Excel file and writes the column names and data records to the specified sheet.
+// The document title defaults to the name of the source file and the creation time to now, see WithProperties.
+// The file is written to a temporary file in the same directory and then renamed, so an existing
+// file is replaced atomically and an interrupted write never leaves a partial file behind.
 // Returns an error if the file cannot be created or written to.
-func (c *CSV) SaveAsExcel(filePath string, sheetName string) error {
+func (c *CSV) SaveAsExcel(filePath string, sheetName string, options ...ExcelOption) error {
+	var opts excelOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	formulas, err := c.compileFormulas(opts.formulas)
+	if err != nil {
+		return err
+	}
 
 	f := excelize.NewFile()
 
@@ -199,18 +364,148 @@ func (c *CSV) SaveAsExcel(filePath string, sheetName string) error {
 		sheetName = "Sheet1"
 	}
 	headerNames := c.GetHeaderNames()
+	for _, formula := range opts.formulas {
+		headerNames = append(headerNames, formula.Name)
+	}
 	f.SetSheetRow(sheetName, "A1", &headerNames)
 
-	for i, record := range c.Records {
-		row := fmt.Sprintf("A%d", i+2)
-		f.SetSheetRow(sheetName, row, &record)
+	rows := len(c.Records) + opts.blankRows
+	for i := 0; i < rows; i++ {
+		if i < len(c.Records) {
+			row := fmt.Sprintf("A%d", i+2)
+			values := excelRow(c.Records[i])
+			f.SetSheetRow(sheetName, row, &values)
+		}
+		for j, formula := range formulas {
+			cell, err := excelize.CoordinatesToCellName(len(c.Headers)+j+1, i+2)
+			if err != nil {
+				return err
+			}
+			if err := f.SetCellFormula(sheetName, cell, formula.cell(i+2)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := c.writeDateFormats(f, sheetName, rows); err != nil {
+		return err
+	}
+	if err := c.writeHyperlinks(f, sheetName, opts.linkText); err != nil {
+		return err
+	}
+	if err := c.writeConditionalFormats(f, sheetName, opts.conditionalFormats, headerNames, rows); err != nil {
+		return err
+	}
+	if err := c.writeDropdowns(f, sheetName, opts.dropdowns, rows); err != nil {
+		return err
+	}
+	if err := c.writeTotals(f, sheetName, opts.totals, len(headerNames), rows); err != nil {
+		return err
+	}
+	if err := c.writePivots(f, sheetName, opts.pivots, headerNames); err != nil {
+		return err
+	}
+	if err := c.writeCharts(f, sheetName, opts.charts, headerNames); err != nil {
+		return err
 	}
-	if err := f.SaveAs(filePath); err != nil {
+	if opts.summary {
+		if err := c.writeSummary(f); err != nil {
+			return err
+		}
+	}
+	if err := c.writeProperties(f, filePath, opts.properties); err != nil {
 		return err
 	}
+	return writeAtomic(filePath, func(w io.Writer) error {
+		_, err := f.WriteTo(w)
+		return err
+	})
+}
+
+// writeDateFormats gives the rows of each date column the format yyyy-mm-dd, or yyyy-mm-dd hh:mm:ss
+// if any of its values has a time of day, so all dates of a column are shown the same way.
+func (c *CSV) writeDateFormats(f *excelize.File, sheetName string, rows int) error {
+	if rows == 0 {
+		return nil
+	}
+	styles := make(map[string]int)
+	for i, column := range c.Headers {
+		if column.Type != DateType {
+			continue
+		}
+		format := "yyyy-mm-dd"
+		for _, record := range c.Records {
+			if t, ok := valueAt(record, i).(time.Time); ok && (t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0) {
+				format = "yyyy-mm-dd hh:mm:ss"
+				break
+			}
+		}
+		style, ok := styles[format]
+		if !ok {
+			var err error
+			if style, err = f.NewStyle(&excelize.Style{CustomNumFmt: &format}); err != nil {
+				return err
+			}
+			styles[format] = style
+		}
+		first, err := excelize.CoordinatesToCellName(i+1, 2)
+		if err != nil {
+			return err
+		}
+		last, err := excelize.CoordinatesToCellName(i+1, rows+1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(sheetName, first, last, style); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// writeAtomic writes a file by calling write with a temporary file in the same directory,
+// which is renamed to filePath once it has been written and synced. The temporary file is
+// removed if writing fails. A replaced file keeps its permissions, while a new file gets the
+// default permissions of the process, as with os.Create.
+func writeAtomic(filePath string, write func(w io.Writer) error) error {
+	tmp, err := createTemp(filePath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if info, err := os.Stat(filePath); err == nil {
+		if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// createTemp creates a new temporary file next to filePath. Unlike os.CreateTemp, the file is
+// created with mode 0666 before the umask is applied, so it can be renamed to filePath as is.
+func createTemp(filePath string) (*os.File, error) {
+	for i := 0; i < 100; i++ {
+		name := filepath.Join(filepath.Dir(filePath), fmt.Sprintf(".%s.%d.tmp", filepath.Base(filePath), rand.Uint32()))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o666)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		return f, err
+	}
+	return nil, fmt.Errorf("failed to create a temporary file for %s", filePath)
+}
+
 // GetHeaderNames returns a Slice with the names of the columns in the CSV file.
 func (c *CSV) GetHeaderNames() []string {
 	columnNames := make([]string, len(c.Headers))
@@ -220,25 +515,146 @@ func (c *CSV) GetHeaderNames() []string {
 	return columnNames
 }
 
-func Merge(files ...*CSV) (*CSV, error) {
+// MergeOption configures how files are merged by Merge.
+type MergeOption func(*mergeOptions)
+
+type mergeOptions struct {
+	dedupe           bool
+	dedupeKeys       []string
+	keepLast         bool
+	sourceColumn     string
+	sourceFullPath   bool
+	sourceLineColumn string
+}
+
+// WithDedupe removes duplicate rows from the merged data. Rows are compared on the given key
+// columns, or on all columns if no keys are given. If keepLast is true the last occurrence of
+// a duplicate is kept, otherwise the first.
+func WithDedupe(keys []string, keepLast bool) MergeOption {
+	return func(o *mergeOptions) {
+		o.dedupe = true
+		o.dedupeKeys = keys
+		o.keepLast = keepLast
+	}
+}
+
+// WithSourceColumn appends a column with the given name holding the file each row came from.
+// The column contains the base name of the file, or the full path if fullPath is true.
+func WithSourceColumn(name string, fullPath bool) MergeOption {
+	return func(o *mergeOptions) {
+		o.sourceColumn = name
+		o.sourceFullPath = fullPath
+	}
+}
+
+// WithSourceLine appends a column with the given name holding the line number
+// in the source file on which each row starts.
+func WithSourceLine(name string) MergeOption {
+	return func(o *mergeOptions) {
+		o.sourceLineColumn = name
+	}
+}
+
+// Merge combines the records of multiple CSV files into a single CSV, using the headers of the first file.
+// Returns the merged CSV, the number of rows removed as duplicates and an error if the files
+// have an inconsistent number of columns.
+func Merge(files []*CSV, options ...MergeOption) (*CSV, int, error) {
 	if len(files) == 0 {
-		return nil, fmt.Errorf("no files to merge")
+		return nil, 0, fmt.Errorf("no files to merge")
+	}
+	m := NewMerger(options...)
+	for _, file := range files {
+		if err := m.Add(file); err != nil {
+			return nil, 0, err
+		}
 	}
-	columnCount := len(files[0].Headers)
+	return m.Result()
+}
 
-	for _, file := range files[1:] {
-		if columnCount != len(file.Headers) {
-			return nil, fmt.Errorf("inconsistent number of columns in either %s or %s", file.FilePath, files[0].FilePath)
+// Merger combines CSV files one at a time, so that the records of each file can be
+// appended as soon as it has been read, without holding all files in memory.
+type Merger struct {
+	opts      mergeOptions
+	merged    *CSV
+	firstPath string
+	columns   int
+}
+
+// NewMerger creates a new Merger with the specified options.
+func NewMerger(options ...MergeOption) *Merger {
+	m := &Merger{}
+	for _, option := range options {
+		option(&m.opts)
+	}
+	return m
+}
+
+// Add appends the records of the file to the merged data. The first file added
+// determines the headers. Returns an error if the file has a different number of columns.
+func (m *Merger) Add(file *CSV) error {
+	if m.merged == nil {
+		headers := slices.Clone(file.Headers)
+		if m.opts.sourceColumn != "" {
+			headers = append(headers, Column{Name: m.opts.sourceColumn, Type: StringType})
+		}
+		if m.opts.sourceLineColumn != "" {
+			headers = append(headers, Column{Name: m.opts.sourceLineColumn, Type: IntegerType})
 		}
+		m.merged = New(
+			WithHeaders(headers),
+			WithDelimiter(file.Delimiter),
+			WithRecords(make([][]Value, 0)),
+		)
+		m.firstPath = file.FilePath
+		m.columns = len(file.Headers)
+	} else if m.columns != len(file.Headers) {
+		return fmt.Errorf("inconsistent number of columns in either %s or %s", file.FilePath, m.firstPath)
 	}
-	mergedFiles := make([][]Value, 0)
-	for _, file := range files {
-		mergedFiles = append(mergedFiles, file.Records...)
-	}
-	f := New(
-		WithHeaders(files[0].Headers),
-		WithDelimiter(files[0].Delimiter),
-		WithRecords(mergedFiles),
-	)
-	return f, nil
+
+	if m.opts.sourceColumn == "" && m.opts.sourceLineColumn == "" {
+		m.merged.Records = append(m.merged.Records, file.Records...)
+		return nil
+	}
+	source := filepath.Base(file.FilePath)
+	if m.opts.sourceFullPath {
+		source = file.FilePath
+	}
+	for i, record := range file.Records {
+		record = slices.Clip(record)
+		if m.opts.sourceColumn != "" {
+			record = append(record, source)
+		}
+		if m.opts.sourceLineColumn != "" {
+			var line Value
+			if i < len(file.LineNumbers) {
+				line = int64(file.LineNumbers[i])
+			}
+			record = append(record, line)
+		}
+		m.merged.Records = append(m.merged.Records, record)
+	}
+	return nil
+}
+
+// Result returns the merged CSV and the number of rows removed as duplicates.
+// Returns an error if no files have been added or the de-duplication keys are invalid.
+func (m *Merger) Result() (*CSV, int, error) {
+	if m.merged == nil {
+		return nil, 0, fmt.Errorf("no files to merge")
+	}
+	dropped := 0
+	if m.opts.dedupe {
+		indexes, err := m.merged.keyIndexes(m.opts.dedupeKeys)
+		if err != nil {
+			return nil, 0, err
+		}
+		// Whole-row comparison only considers the input columns, as the source columns differ per file.
+		if len(indexes) == 0 {
+			for i := range m.columns {
+				indexes = append(indexes, i)
+			}
+		}
+		dropped = m.merged.dedupe(indexes, m.opts.keepLast)
+	}
+	return m.merged, dropped, nil
 }
@@ -8,8 +8,13 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
 )
 
 func Test_readCSV(t *testing.T) {
@@ -207,6 +212,22 @@ func Test_CSV_ConvertColumnTypes(t *testing.T) {
 				{"text3", "text4"},
 			},
 		},
+		{
+			name: "Convert string to date",
+			csv: &CSV{
+				Headers: []Column{
+					{Name: "Column1", Type: DateType},
+				},
+				Records: [][]Value{
+					{"2024-01-31"},
+					{"invalid"},
+				},
+			},
+			expected: [][]Value{
+				{time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+				{"invalid"},
+			},
+		},
 		{
 			name: "Mixed conversion",
 			csv: &CSV{
@@ -271,6 +292,53 @@ func Test_CSV_InferColumnTypes(t *testing.T) {
 				{Name: "Column3", Type: StringType},
 			},
 		},
+		{
+			name: "Infer hyperlinks and email addresses",
+			csv: &CSV{
+				Headers: []Column{
+					{Name: "url", Type: StringType},
+					{Name: "email", Type: StringType},
+					{Name: "mixed", Type: StringType},
+				},
+				Records: [][]Value{
+					{"https://example.com/a?b=1", "anna@example.com", "https://example.com"},
+					{"http://example.org", "bo.b+tag@mail.example.se", "bob@example.com"},
+				},
+			},
+			expected: []Column{
+				{Name: "url", Type: HyperlinkType},
+				{Name: "email", Type: EmailType},
+				{Name: "mixed", Type: StringType},
+			},
+		},
+		{
+			name: "Infer mixed integers and floats as floats",
+			csv: &CSV{
+				Headers: []Column{{Name: "amount", Type: StringType}},
+				Records: [][]Value{{"1.5"}, {"2.5"}, {"3"}},
+			},
+			expected: []Column{{Name: "amount", Type: FloatType}},
+		},
+		{
+			name: "Infer types ignoring empty values",
+			csv: &CSV{
+				Headers: []Column{
+					{Name: "amount", Type: StringType},
+					{Name: "date", Type: StringType},
+					{Name: "empty", Type: StringType},
+				},
+				Records: [][]Value{
+					{"10", "", ""},
+					{"", "2024-01-31", " "},
+					{"5", "2024-02-01", ""},
+				},
+			},
+			expected: []Column{
+				{Name: "amount", Type: IntegerType},
+				{Name: "date", Type: DateType},
+				{Name: "empty", Type: StringType},
+			},
+		},
 		{
 			name: "Infer types for all strings",
 			csv: &CSV{
@@ -322,6 +390,23 @@ func Test_CSV_InferColumnTypes(t *testing.T) {
 				{Name: "Column2", Type: FloatType},
 			},
 		},
+		{
+			name: "Infer types for dates",
+			csv: &CSV{
+				Headers: []Column{
+					{Name: "Column1", Type: StringType},
+					{Name: "Column2", Type: StringType},
+				},
+				Records: [][]Value{
+					{"2024-01-01", "2024-01-01 10:00:00"},
+					{"2024-02-29", "2024-03-01"},
+				},
+			},
+			expected: []Column{
+				{Name: "Column1", Type: DateType},
+				{Name: "Column2", Type: DateType},
+			},
+		},
 		{
 			name: "Infer types for mixed valid and invalid data",
 			csv: &CSV{
@@ -383,3 +468,295 @@ func Test_CSV_GetHeaderNames(t *testing.T) {
 		})
 	}
 }
+func Test_Merge(t *testing.T) {
+	newFiles := func() []*CSV {
+		headers := []Column{{Name: "id", Type: StringType}, {Name: "value", Type: StringType}}
+		return []*CSV{
+			{FilePath: "data/a.csv", Headers: headers, Records: [][]Value{{"1", "a"}, {"2", "b"}}, LineNumbers: []int{2, 3}},
+			{FilePath: "data/b.csv", Headers: headers, Records: [][]Value{{"1", "a"}, {"2", "c"}}, LineNumbers: []int{2, 4}},
+		}
+	}
+	tests := []struct {
+		name            string
+		files           []*CSV
+		options         []MergeOption
+		expected        [][]Value
+		expectedDropped int
+		wantErr         bool
+	}{
+		{
+			name:     "Merge without options",
+			files:    newFiles(),
+			expected: [][]Value{{"1", "a"}, {"2", "b"}, {"1", "a"}, {"2", "c"}},
+		},
+		{
+			name:            "Dedupe whole rows",
+			files:           newFiles(),
+			options:         []MergeOption{WithDedupe(nil, false)},
+			expected:        [][]Value{{"1", "a"}, {"2", "b"}, {"2", "c"}},
+			expectedDropped: 1,
+		},
+		{
+			name:            "Dedupe on key keeping first",
+			files:           newFiles(),
+			options:         []MergeOption{WithDedupe([]string{"id"}, false)},
+			expected:        [][]Value{{"1", "a"}, {"2", "b"}},
+			expectedDropped: 2,
+		},
+		{
+			name:            "Dedupe on key keeping last",
+			files:           newFiles(),
+			options:         []MergeOption{WithDedupe([]string{"id"}, true)},
+			expected:        [][]Value{{"1", "a"}, {"2", "c"}},
+			expectedDropped: 2,
+		},
+		{
+			name:    "Source file and line columns",
+			files:   newFiles(),
+			options: []MergeOption{WithSourceColumn("source_file", false), WithSourceLine("source_line")},
+			expected: [][]Value{
+				{"1", "a", "a.csv", int64(2)},
+				{"2", "b", "a.csv", int64(3)},
+				{"1", "a", "b.csv", int64(2)},
+				{"2", "c", "b.csv", int64(4)},
+			},
+		},
+		{
+			name:            "Dedupe whole rows with source full path",
+			files:           newFiles(),
+			options:         []MergeOption{WithSourceColumn("source", true), WithDedupe(nil, false)},
+			expected:        [][]Value{{"1", "a", "data/a.csv"}, {"2", "b", "data/a.csv"}, {"2", "c", "data/b.csv"}},
+			expectedDropped: 1,
+		},
+		{
+			name: "Inconsistent number of columns",
+			files: []*CSV{
+				{Headers: []Column{{Name: "id"}}},
+				{Headers: []Column{{Name: "id"}, {Name: "value"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "No files",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, dropped, err := Merge(tt.files, tt.options...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Merge() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.Records, tt.expected) {
+				t.Errorf("Merge() = %v, expected %v", got.Records, tt.expected)
+			}
+			if dropped != tt.expectedDropped {
+				t.Errorf("Merge() dropped = %v, expected %v", dropped, tt.expectedDropped)
+			}
+		})
+	}
+}
+
+func Test_readCSVLines(t *testing.T) {
+	input := "a,b\n1,2\n3\n\"4\n\",5\n6,7,8\n9,10\n"
+	tests := []struct {
+		name             string
+		skipBadRows      bool
+		expected         [][]string
+		expectedLines    []int
+		expectedRejected int
+		wantErr          bool
+	}{
+		{
+			name:    "Fail on malformed row",
+			wantErr: true,
+		},
+		{
+			name:             "Skip malformed rows",
+			skipBadRows:      true,
+			expected:         [][]string{{"a", "b"}, {"1", "2"}, {"4\n", "5"}, {"9", "10"}},
+			expectedLines:    []int{1, 2, 4, 7},
+			expectedRejected: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, lines, rejected, err := readCSVLines(bytes.NewBufferString(input), ',', tt.skipBadRows)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("readCSVLines() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("readCSVLines() = %q, expected %q", got, tt.expected)
+			}
+			if !reflect.DeepEqual(lines, tt.expectedLines) {
+				t.Errorf("readCSVLines() lines = %v, expected %v", lines, tt.expectedLines)
+			}
+			if rejected != tt.expectedRejected {
+				t.Errorf("readCSVLines() rejected = %v, expected %v", rejected, tt.expectedRejected)
+			}
+		})
+	}
+}
+
+func Test_CSV_SaveAsExcel(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.xlsx")
+	if err := os.WriteFile(path, []byte("previous content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := New(
+		WithHeaders([]Column{{Name: "id", Type: IntegerType}}),
+		WithRecords([][]Value{{int64(1)}, {int64(2)}}),
+	)
+	if err := c.SaveAsExcel(path, ""); err != nil {
+		t.Fatalf("SaveAsExcel() error = %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	defer f.Close()
+	rows, err := f.GetRows("Sheet1")
+	if err != nil {
+		t.Fatalf("GetRows() error = %v", err)
+	}
+	expected := [][]string{{"id"}, {"1"}, {"2"}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("SaveAsExcel() rows = %v, expected %v", rows, expected)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("SaveAsExcel() left temporary files behind: %v", entries)
+	}
+}
+
+func Test_writeAtomic_Permissions(t *testing.T) {
+	dir := t.TempDir()
+	reference, err := os.Create(filepath.Join(dir, "reference"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference.Close()
+	defaultInfo, err := os.Stat(reference.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(w io.Writer) error {
+		_, err := w.Write([]byte("content"))
+		return err
+	}
+
+	path := filepath.Join(dir, "new.xlsx")
+	if err := writeAtomic(path, write); err != nil {
+		t.Fatalf("writeAtomic() error = %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != defaultInfo.Mode().Perm() {
+		t.Errorf("writeAtomic() new file mode = %v, expected %v", info.Mode().Perm(), defaultInfo.Mode().Perm())
+	}
+
+	path = filepath.Join(dir, "existing.xlsx")
+	if err := os.WriteFile(path, []byte("previous content"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeAtomic(path, write); err != nil {
+		t.Fatalf("writeAtomic() error = %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o640 {
+		t.Errorf("writeAtomic() replaced file mode = %v, expected %v", info.Mode().Perm(), os.FileMode(0o640))
+	}
+}
+
+func Test_writeAtomic_Failure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.xlsx")
+	if err := os.WriteFile(path, []byte("previous content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := writeAtomic(path, func(w io.Writer) error {
+		w.Write([]byte("partial"))
+		return fmt.Errorf("interrupted")
+	})
+	if err == nil {
+		t.Fatalf("writeAtomic() expected error")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil || string(content) != "previous content" {
+		t.Errorf("writeAtomic() changed the existing file to %q (%v)", content, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("writeAtomic() left temporary files behind: %v", entries)
+	}
+}
+
+func Test_CSV_SaveAsExcel_DateFormats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xlsx")
+	c := New(
+		WithHeaders([]Column{{Name: "day", Type: StringType}, {Name: "at", Type: StringType}}),
+		WithRecords([][]Value{
+			{"2024-01-05", "2024-01-05"},
+			{"2023-12-07", "2023-12-07"},
+			{"2024-02-01", "2024-02-01"},
+			{"2024-03-01", "2024-03-01 10:30:00"},
+		}),
+	)
+	c.InferColumnTypes()
+	c.ConvertColumnTypes()
+	if err := c.SaveAsExcel(path, ""); err != nil {
+		t.Fatalf("SaveAsExcel() error = %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	defer f.Close()
+	tests := []struct {
+		cell     string
+		expected string
+		format   string
+	}{
+		{cell: "A2", expected: "2024-01-05", format: "yyyy-mm-dd"},
+		{cell: "A3", expected: "2023-12-07", format: "yyyy-mm-dd"},
+		{cell: "A4", expected: "2024-02-01", format: "yyyy-mm-dd"},
+		{cell: "B2", expected: "2024-01-05 00:00:00", format: "yyyy-mm-dd hh:mm:ss"},
+		{cell: "B5", expected: "2024-03-01 10:30:00", format: "yyyy-mm-dd hh:mm:ss"},
+	}
+	for _, tt := range tests {
+		value, err := f.GetCellValue("Sheet1", tt.cell)
+		if err != nil {
+			t.Fatalf("GetCellValue() error = %v", err)
+		}
+		if value != tt.expected {
+			t.Errorf("SaveAsExcel() %s = %q, expected %q", tt.cell, value, tt.expected)
+		}
+		styleID, err := f.GetCellStyle("Sheet1", tt.cell)
+		if err != nil {
+			t.Fatalf("GetCellStyle() error = %v", err)
+		}
+		style, err := f.GetStyle(styleID)
+		if err != nil {
+			t.Fatalf("GetStyle() error = %v", err)
+		}
+		if style.CustomNumFmt == nil || *style.CustomNumFmt != tt.format {
+			t.Errorf("SaveAsExcel() %s number format = %v, expected %s", tt.cell, style.CustomNumFmt, tt.format)
+		}
+	}
+}
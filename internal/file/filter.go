@@ -0,0 +1,448 @@
+package file
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Filter keeps only the records for which the expression evaluates to true.
+//
+// An expression consists of comparisons between columns and literals, combined with
+// && (and), || (or), ! (not) and parentheses. The supported comparison operators are
+// ==, !=, <, <=, >, >=, =~ (regex match) and !~ (regex does not match). For example:
+//
+//	amount > 100 && country == "SE"
+//	date >= 2024-01-01
+//	[Product Name] =~ "^Widget"
+//
+// Columns are referenced by name, with names containing spaces or operators enclosed
+// in square brackets. Numbers and dates are compared by value, everything else as text.
+// Dates may include a time and time zone, e.g. 2024-01-01T10:00:00Z. Empty values and values
+// that are not numbers or dates never match a comparison with a number or date, except !=.
+func (c *CSV) Filter(expression string) error {
+	cond, err := parseExpression(expression, c)
+	if err != nil {
+		return err
+	}
+	filtered := make([][]Value, 0, len(c.Records))
+	for _, record := range c.Records {
+		if cond.eval(record) {
+			filtered = append(filtered, record)
+		}
+	}
+	c.Records = filtered
+	return nil
+}
+
+// condition is a node in a parsed filter expression.
+type condition interface {
+	eval(record []Value) bool
+}
+
+type andCondition struct{ left, right condition }
+
+func (a andCondition) eval(record []Value) bool { return a.left.eval(record) && a.right.eval(record) }
+
+type orCondition struct{ left, right condition }
+
+func (o orCondition) eval(record []Value) bool { return o.left.eval(record) || o.right.eval(record) }
+
+type notCondition struct{ cond condition }
+
+func (n notCondition) eval(record []Value) bool { return !n.cond.eval(record) }
+
+// operand is either a column reference or a literal value.
+type operand struct {
+	column int
+	value  Value
+}
+
+func (o operand) resolve(record []Value) Value {
+	if o.column < 0 {
+		return o.value
+	}
+	if o.column < len(record) {
+		return record[o.column]
+	}
+	return nil
+}
+
+type comparison struct {
+	left, right operand
+	operator    string
+	pattern     *regexp.Regexp
+}
+
+func (c comparison) eval(record []Value) bool {
+	left := c.left.resolve(record)
+	switch c.operator {
+	case "=~":
+		return c.pattern.MatchString(FormatValue(left))
+	case "!~":
+		return !c.pattern.MatchString(FormatValue(left))
+	}
+	result, ok := c.compare(left, c.right.resolve(record))
+	if !ok {
+		return c.operator == "!="
+	}
+	switch c.operator {
+	case "==":
+		return result == 0
+	case "!=":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	}
+	return false
+}
+
+// literal returns the value of the literal operand of the comparison, or nil if both are columns.
+func (c comparison) literal() Value {
+	if c.right.column < 0 {
+		return c.right.value
+	}
+	if c.left.column < 0 {
+		return c.left.value
+	}
+	return nil
+}
+
+// compare compares the values of the operands and returns -1, 0 or 1. If one of the operands is
+// a number or date literal, the other value must be a number or date too, and ok is false if it is
+// empty or cannot be converted, so such values only satisfy !=.
+func (c comparison) compare(left, right Value) (result int, ok bool) {
+	switch c.literal().(type) {
+	case float64:
+		x, xOK := toFloat(left)
+		y, yOK := toFloat(right)
+		if !xOK || !yOK {
+			return 0, false
+		}
+		return cmp.Compare(x, y), true
+	case time.Time:
+		x, xOK := toTime(left)
+		y, yOK := toTime(right)
+		if !xOK || !yOK {
+			return 0, false
+		}
+		return x.Compare(y), true
+	}
+	return compareValues(left, right), true
+}
+
+// compareValues compares two values and returns -1, 0 or 1.
+// Values are compared numerically if both are numbers, chronologically if both are dates
+// and lexically otherwise. String values that can be parsed as numbers or dates are
+// compared as such, so the comparison also works on records that have not been converted.
+func compareValues(a, b Value) int {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	if x, ok := toTime(a); ok {
+		if y, ok := toTime(b); ok {
+			return x.Compare(y)
+		}
+	}
+	return strings.Compare(FormatValue(a), FormatValue(b))
+}
+
+// toFloat returns the value as a float64 if it is, or can be parsed as, a number.
+func toFloat(v Value) (float64, bool) {
+	switch value := v.(type) {
+	case float64:
+		return value, true
+	case int64:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case string:
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed, true
+		}
+	}
+	return 0, false
+}
+
+// toTime returns the value as a time.Time if it is, or can be parsed as, a date.
+func toTime(v Value) (time.Time, bool) {
+	switch value := v.(type) {
+	case time.Time:
+		return value, true
+	case string:
+		return parseDate(value)
+	}
+	return time.Time{}, false
+}
+
+// FormatValue returns the textual representation of a value.
+func FormatValue(v Value) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case time.Time:
+		if value.Hour() == 0 && value.Minute() == 0 && value.Second() == 0 {
+			return value.Format(time.DateOnly)
+		}
+		return value.Format(time.DateTime)
+	}
+	return fmt.Sprint(v)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenColumn
+	tokenString
+	tokenNumber
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// tokenize splits a filter expression into tokens.
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				sb.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string in expression %q", expression)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sb.String()})
+			i = j + 1
+		case r == '[':
+			j := i + 1
+			for j < len(runes) && runes[j] != ']' {
+				j++
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated column name in expression %q", expression)
+			}
+			tokens = append(tokens, token{kind: tokenColumn, text: string(runes[i+1 : j])})
+			i = j + 1
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]) && expectsOperand(tokens)):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || strings.ContainsRune(".-+:TZ", runes[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[i:j])})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenColumn, text: string(runes[i:j])})
+			i = j
+		default:
+			operator := ""
+			for _, op := range []string{"&&", "||", "==", "!=", ">=", "<=", "=~", "!~", ">", "<", "!", "(", ")"} {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					operator = op
+					break
+				}
+			}
+			if operator == "" {
+				return nil, fmt.Errorf("unexpected character %q in expression %q", r, expression)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: operator})
+			i += len(operator)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+// expectsOperand reports whether the next token is expected to be an operand,
+// which is used to tell negative numbers apart from dates and ranges.
+func expectsOperand(tokens []token) bool {
+	return len(tokens) == 0 || tokens[len(tokens)-1].kind == tokenOperator
+}
+
+// parser is a recursive descent parser for filter expressions.
+type parser struct {
+	tokens []token
+	pos    int
+	csv    *CSV
+}
+
+// parseExpression parses a filter expression, resolving column references against the headers of c.
+func parseExpression(expression string, c *CSV) (condition, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, csv: c}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q in expression %q", p.peek().text, expression)
+	}
+	return cond, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOperator(text string) bool {
+	t := p.peek()
+	return t.kind == tokenOperator && t.text == text
+}
+
+func (p *parser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orCondition{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (condition, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("&&") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andCondition{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (condition, error) {
+	if p.isOperator("!") {
+		p.next()
+		cond, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notCondition{cond}, nil
+	}
+	if p.isOperator("(") {
+		p.next()
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isOperator(")") {
+			return nil, fmt.Errorf("expected ) but found %q", p.peek().text)
+		}
+		p.next()
+		return cond, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (condition, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	operator := p.next()
+	switch operator.text {
+	case "==", "!=", "<", "<=", ">", ">=", "=~", "!~":
+	default:
+		return nil, fmt.Errorf("expected comparison operator but found %q", operator.text)
+	}
+	if operator.text == "=~" || operator.text == "!~" {
+		t := p.next()
+		if t.kind != tokenString {
+			return nil, fmt.Errorf("expected quoted pattern after %s but found %q", operator.text, t.text)
+		}
+		pattern, err := regexp.Compile(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", t.text, err)
+		}
+		return comparison{left: left, operator: operator.text, pattern: pattern}, nil
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return comparison{left: left, right: right, operator: operator.text}, nil
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenColumn:
+		index, err := p.csv.ColumnIndex(t.text)
+		if err != nil {
+			return operand{}, err
+		}
+		return operand{column: index}, nil
+	case tokenString:
+		return operand{column: -1, value: t.text}, nil
+	case tokenNumber:
+		if value, err := strconv.ParseFloat(t.text, 64); err == nil {
+			return operand{column: -1, value: value}, nil
+		}
+		if value, ok := parseDate(t.text); ok {
+			return operand{column: -1, value: value}, nil
+		}
+		return operand{}, fmt.Errorf("invalid number or date %q", t.text)
+	}
+	if t.kind == tokenEOF {
+		return operand{}, fmt.Errorf("unexpected end of expression")
+	}
+	return operand{}, fmt.Errorf("expected column or value but found %q", t.text)
+}
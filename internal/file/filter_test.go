@@ -0,0 +1,162 @@
+package file
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_CSV_Filter(t *testing.T) {
+	newCSV := func() *CSV {
+		return &CSV{
+			Headers: []Column{
+				{Name: "id", Type: IntegerType},
+				{Name: "amount", Type: FloatType},
+				{Name: "country", Type: StringType},
+				{Name: "order date", Type: DateType},
+			},
+			Records: [][]Value{
+				{int64(1), float64(50), "SE", time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)},
+				{int64(2), float64(150), "SE", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+				{int64(3), float64(250), "NO", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+				{int64(4), float64(-5), "DK", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		}
+	}
+	tests := []struct {
+		name       string
+		expression string
+		expected   []Value
+		wantErr    bool
+	}{
+		{
+			name:       "Numeric and string comparison",
+			expression: `amount > 100 && country == "SE"`,
+			expected:   []Value{int64(2)},
+		},
+		{
+			name:       "Date comparison",
+			expression: `[order date] >= 2024-01-01`,
+			expected:   []Value{int64(2), int64(3), int64(4)},
+		},
+		{
+			name:       "Negative number",
+			expression: `amount < -1`,
+			expected:   []Value{int64(4)},
+		},
+		{
+			name:       "Regex match",
+			expression: `country =~ "^(NO|DK)$"`,
+			expected:   []Value{int64(3), int64(4)},
+		},
+		{
+			name:       "Or, not and parentheses",
+			expression: `!(country == "SE") || (id == 1 || id == 2) && amount >= 150`,
+			expected:   []Value{int64(2), int64(3), int64(4)},
+		},
+		{
+			name:       "Unknown column",
+			expression: `missing == 1`,
+			wantErr:    true,
+		},
+		{
+			name:       "Missing operator",
+			expression: `amount 100`,
+			wantErr:    true,
+		},
+		{
+			name:       "Unterminated string",
+			expression: `country == "SE`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCSV()
+			err := c.Filter(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Filter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			got := make([]Value, len(c.Records))
+			for i, record := range c.Records {
+				got[i] = record[0]
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Filter() ids = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_CSV_Filter_NullableAndMixed(t *testing.T) {
+	newCSV := func() *CSV {
+		return &CSV{
+			Headers: []Column{
+				{Name: "id", Type: IntegerType},
+				{Name: "amount", Type: IntegerType},
+				{Name: "ts", Type: DateType},
+			},
+			Records: [][]Value{
+				{int64(1), int64(50), time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)},
+				{int64(2), "", time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)},
+				{int64(3), "N/A", ""},
+				{int64(4), int64(150), nil},
+			},
+		}
+	}
+	tests := []struct {
+		name       string
+		expression string
+		expected   []Value
+	}{
+		{name: "Less than skips empty values", expression: `amount < 100`, expected: []Value{int64(1)}},
+		{name: "Greater than skips text", expression: `amount > 100`, expected: []Value{int64(4)}},
+		{name: "Equal skips empty values", expression: `amount == 0`, expected: []Value{}},
+		{name: "Not equal keeps empty values", expression: `amount != 50`, expected: []Value{int64(2), int64(3), int64(4)}},
+		{name: "Literal on the left", expression: `100 >= amount`, expected: []Value{int64(1)}},
+		{name: "Date skips empty values", expression: `ts < 2025-01-01`, expected: []Value{int64(1), int64(2)}},
+		{name: "Unquoted RFC 3339 time", expression: `ts >= 2024-01-01T10:00:00Z`, expected: []Value{int64(2)}},
+		{name: "RFC 3339 time with offset", expression: `ts < 2024-01-01T10:30:00+01:00`, expected: []Value{int64(1)}},
+		{name: "Quoted empty string", expression: `amount == ""`, expected: []Value{int64(2)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCSV()
+			if err := c.Filter(tt.expression); err != nil {
+				t.Fatalf("Filter() error = %v", err)
+			}
+			got := make([]Value, len(c.Records))
+			for i, record := range c.Records {
+				got[i] = record[0]
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Filter() ids = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_compareValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Value
+		expected int
+	}{
+		{name: "Integers and floats", a: int64(10), b: float64(9.5), expected: 1},
+		{name: "Numeric strings", a: "9", b: "10", expected: -1},
+		{name: "Dates", a: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), b: "2024-01-01", expected: 0},
+		{name: "Strings", a: "apple", b: "banana", expected: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareValues(tt.a, tt.b); got != tt.expected {
+				t.Errorf("compareValues() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
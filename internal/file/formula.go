@@ -0,0 +1,106 @@
+package file
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Formula is a computed column that is written to Excel as a formula, so the calculation
+// can be seen and audited in the workbook.
+type Formula struct {
+	// Name is the name of the computed column.
+	Name string
+	// Expression is the Excel formula for a single row, with columns referenced by name
+	// in square brackets, e.g. "[Qty]*[Price]" or "ROUND([Amount]*1.25, 2)".
+	Expression string
+}
+
+// columnReference matches a column reference in a formula expression.
+var columnReference = regexp.MustCompile(`\[([^\]]+)\]`)
+
+// ParseFormula parses a formula definition of the form "Name=Expression", e.g. "Total=[Qty]*[Price]".
+func ParseFormula(definition string) (Formula, error) {
+	name, expression, ok := strings.Cut(definition, "=")
+	name, expression = strings.TrimSpace(name), strings.TrimSpace(expression)
+	if !ok || name == "" || expression == "" {
+		return Formula{}, fmt.Errorf("invalid formula %q, expected Name=Expression", definition)
+	}
+	return Formula{Name: name, Expression: strings.TrimPrefix(expression, "=")}, nil
+}
+
+// WithFormulas appends a column for each formula, after the columns of the CSV.
+// A formula can reference the columns of the CSV and the formulas before it.
+func WithFormulas(formulas ...Formula) ExcelOption {
+	return func(o *excelOptions) {
+		o.formulas = append(o.formulas, formulas...)
+	}
+}
+
+// CheckFormulas returns an error if a formula references a column that does not exist.
+func (c *CSV) CheckFormulas(formulas ...Formula) error {
+	_, err := c.compileFormulas(formulas)
+	return err
+}
+
+// compiledFormula is a formula expression split around its column references.
+// It has one more text than columns, and each column is followed by the row number.
+type compiledFormula struct {
+	texts   []string
+	columns []string
+}
+
+// compileFormulas translates the column references of the formulas into column letters.
+// Formula columns are numbered after the columns of the CSV, in the order they are given.
+func (c *CSV) compileFormulas(formulas []Formula) ([]compiledFormula, error) {
+	compiled := make([]compiledFormula, len(formulas))
+	for i, formula := range formulas {
+		expression := formula.Expression
+		last := 0
+		for _, match := range columnReference.FindAllStringSubmatchIndex(expression, -1) {
+			index, err := c.formulaColumn(expression[match[2]:match[3]], formulas[:i])
+			if err != nil {
+				return nil, fmt.Errorf("formula %s: %w", formula.Name, err)
+			}
+			letters, err := excelize.ColumnNumberToName(index + 1)
+			if err != nil {
+				return nil, err
+			}
+			compiled[i].texts = append(compiled[i].texts, expression[last:match[0]])
+			compiled[i].columns = append(compiled[i].columns, letters)
+			last = match[1]
+		}
+		compiled[i].texts = append(compiled[i].texts, expression[last:])
+	}
+	return compiled, nil
+}
+
+// formulaColumn returns the index of the referenced column, which is either a column of the CSV
+// or one of the formulas before the one being compiled.
+func (c *CSV) formulaColumn(reference string, previous []Formula) (int, error) {
+	index, err := c.ColumnIndex(reference)
+	if err == nil {
+		return index, nil
+	}
+	for i := len(previous) - 1; i >= 0; i-- {
+		if previous[i].Name == reference {
+			return len(c.Headers) + i, nil
+		}
+	}
+	return -1, err
+}
+
+// cell returns the formula for the given row.
+func (f compiledFormula) cell(row int) string {
+	var sb strings.Builder
+	for i, column := range f.columns {
+		sb.WriteString(f.texts[i])
+		sb.WriteString(column)
+		sb.WriteString(strconv.Itoa(row))
+	}
+	sb.WriteString(f.texts[len(f.texts)-1])
+	return sb.String()
+}
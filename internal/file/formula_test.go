@@ -0,0 +1,148 @@
+package file
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func Test_ParseFormula(t *testing.T) {
+	tests := []struct {
+		definition string
+		expected   Formula
+		wantErr    bool
+	}{
+		{definition: "Total=[Qty]*[Price]", expected: Formula{Name: "Total", Expression: "[Qty]*[Price]"}},
+		{definition: " Net = =[Gross]/1.25", expected: Formula{Name: "Net", Expression: "[Gross]/1.25"}},
+		{definition: "Check=IF([a]=[b],1,0)", expected: Formula{Name: "Check", Expression: "IF([a]=[b],1,0)"}},
+		{definition: "Total", wantErr: true},
+		{definition: "=[Qty]", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.definition, func(t *testing.T) {
+			got, err := ParseFormula(tt.definition)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFormula() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.expected {
+				t.Errorf("ParseFormula() = %+v, expected %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_CSV_compileFormulas(t *testing.T) {
+	c := &CSV{Headers: []Column{{Name: "Qty"}, {Name: "Unit Price"}, {Name: "Discount"}}}
+	tests := []struct {
+		name     string
+		formulas []Formula
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "Column references",
+			formulas: []Formula{{Name: "Total", Expression: "[Qty]*[Unit Price]-[Discount]"}},
+			expected: []string{"A5*B5-C5"},
+		},
+		{
+			name: "Reference to previous formula",
+			formulas: []Formula{
+				{Name: "Total", Expression: "[Qty]*[Unit Price]"},
+				{Name: "VAT", Expression: "ROUND([Total]*0.25, 2)"},
+			},
+			expected: []string{"A5*B5", "ROUND(D5*0.25, 2)"},
+		},
+		{
+			name:     "No references",
+			formulas: []Formula{{Name: "Today", Expression: "TODAY()"}},
+			expected: []string{"TODAY()"},
+		},
+		{
+			name:     "Unknown column",
+			formulas: []Formula{{Name: "Total", Expression: "[Qty]*[Price]"}},
+			wantErr:  true,
+		},
+		{
+			name: "Reference to later formula",
+			formulas: []Formula{
+				{Name: "VAT", Expression: "[Total]*0.25"},
+				{Name: "Total", Expression: "[Qty]*[Unit Price]"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled, err := c.compileFormulas(tt.formulas)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("compileFormulas() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, formula := range compiled {
+				got = append(got, formula.cell(5))
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("compileFormulas() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_CSV_SaveAsExcel_Formulas(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xlsx")
+	c := New(
+		WithHeaders([]Column{{Name: "Qty", Type: IntegerType}, {Name: "Price", Type: FloatType}}),
+		WithRecords([][]Value{{int64(2), 1.5}, {int64(3), 2.0}}),
+	)
+	if err := c.SaveAsExcel(path, "", WithFormulas(Formula{Name: "Total", Expression: "[Qty]*[Price]"})); err != nil {
+		t.Fatalf("SaveAsExcel() error = %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	defer f.Close()
+	if header, _ := f.GetCellValue("Sheet1", "C1"); header != "Total" {
+		t.Errorf("SaveAsExcel() header = %q, expected Total", header)
+	}
+	for cell, expected := range map[string]string{"C2": "A2*B2", "C3": "A3*B3"} {
+		formula, err := f.GetCellFormula("Sheet1", cell)
+		if err != nil {
+			t.Fatalf("GetCellFormula() error = %v", err)
+		}
+		if formula != expected {
+			t.Errorf("SaveAsExcel() formula in %s = %q, expected %q", cell, formula, expected)
+		}
+	}
+}
+
+func Test_CSV_SaveAsExcel_FormulaMissingValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xlsx")
+	c := New(
+		WithHeaders([]Column{{Name: "qty", Type: StringType}, {Name: "price", Type: StringType}}),
+		WithRecords([][]Value{{"2", "1.5"}, {"", "2.5"}, {"3", "4"}}),
+	)
+	c.InferColumnTypes()
+	c.ConvertColumnTypes()
+	total := Formula{Name: "Total", Expression: "[qty]*[price]"}
+	if err := c.SaveAsExcel(path, "", WithFormulas(total), WithTotals("sum")); err != nil {
+		t.Fatalf("SaveAsExcel() error = %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	defer f.Close()
+	if cellType, _ := f.GetCellType("Sheet1", "A3"); cellType != excelize.CellTypeUnset {
+		t.Errorf("SaveAsExcel() missing value in A3 has type %v, expected a blank cell", cellType)
+	}
+	for cell, expected := range map[string]string{"C3": "0", "C5": "15"} {
+		if value, err := f.CalcCellValue("Sheet1", cell); err != nil || value != expected {
+			t.Errorf("CalcCellValue(%s) = %q (%v), expected %q", cell, value, err, expected)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package file
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// maxHyperlinks is the maximum number of hyperlinks Excel allows on a sheet.
+// Cells beyond the limit are written as plain text.
+const maxHyperlinks = 65530
+
+// isHyperlink reports whether the value is an absolute http, https or ftp URL.
+func isHyperlink(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" || strings.ContainsAny(value, " \t") {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "ftp":
+		return true
+	}
+	return false
+}
+
+// isEmail reports whether the value is a plain email address, such as name@example.com.
+func isEmail(value string) bool {
+	address, err := mail.ParseAddress(value)
+	return err == nil && address.Address == value && strings.Contains(value[strings.LastIndex(value, "@"):], ".")
+}
+
+// WithLinkText writes the values of another column as the text of hyperlink and email columns.
+// The map holds the name of the text column for each link column.
+func WithLinkText(columns map[string]string) ExcelOption {
+	return func(o *excelOptions) {
+		if o.linkText == nil {
+			o.linkText = make(map[string]string)
+		}
+		for link, text := range columns {
+			o.linkText[link] = text
+		}
+	}
+}
+
+// CheckLinkText returns an error if a link column is not of a hyperlink or email type,
+// or a text column does not exist.
+func (c *CSV) CheckLinkText(columns map[string]string) error {
+	names := c.GetHeaderNames()
+	for link, text := range columns {
+		index := slices.Index(names, link)
+		if index < 0 {
+			return fmt.Errorf("link column %q not found", link)
+		}
+		if c.Headers[index].Type != HyperlinkType && c.Headers[index].Type != EmailType {
+			return fmt.Errorf("column %q does not contain hyperlinks or email addresses", link)
+		}
+		if !slices.Contains(names, text) {
+			return fmt.Errorf("link text column %q not found", text)
+		}
+	}
+	return nil
+}
+
+// writeHyperlinks turns the cells of hyperlink and email columns into clickable links with link styling.
+// If a text column is given for a link column, its values are shown instead of the links.
+func (c *CSV) writeHyperlinks(f *excelize.File, sheetName string, linkText map[string]string) error {
+	if err := c.CheckLinkText(linkText); err != nil {
+		return err
+	}
+	var style int
+	links := 0
+	names := c.GetHeaderNames()
+	for i, column := range c.Headers {
+		if column.Type != HyperlinkType && column.Type != EmailType {
+			continue
+		}
+		if style == 0 {
+			var err error
+			style, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Color: "0563C1", Underline: "single"}})
+			if err != nil {
+				return err
+			}
+		}
+		textIndex := -1
+		if text, ok := linkText[column.Name]; ok {
+			textIndex = slices.Index(names, text)
+		}
+		for j, record := range c.Records {
+			value := valueAt(record, i)
+			if isNull(value) || links >= maxHyperlinks {
+				continue
+			}
+			cell, err := excelize.CoordinatesToCellName(i+1, j+2)
+			if err != nil {
+				return err
+			}
+			link := FormatValue(value)
+			if column.Type == EmailType && !strings.HasPrefix(strings.ToLower(link), "mailto:") {
+				link = "mailto:" + link
+			}
+			if err := f.SetCellHyperLink(sheetName, cell, link, "External", excelize.HyperlinkOpts{Tooltip: &link}); err != nil {
+				return err
+			}
+			links++
+			if textIndex >= 0 && !isNull(valueAt(record, textIndex)) {
+				if err := f.SetCellValue(sheetName, cell, FormatValue(valueAt(record, textIndex))); err != nil {
+					return err
+				}
+			}
+			if err := f.SetCellStyle(sheetName, cell, cell, style); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
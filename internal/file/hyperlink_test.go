@@ -0,0 +1,93 @@
+package file
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func Test_isHyperlink(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{value: "https://example.com", expected: true},
+		{value: "HTTP://example.com/path?q=1#top", expected: true},
+		{value: "ftp://files.example.com/a.csv", expected: true},
+		{value: "example.com", expected: false},
+		{value: "mailto:anna@example.com", expected: false},
+		{value: "https://", expected: false},
+		{value: "https://example.com/a b", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := isHyperlink(tt.value); got != tt.expected {
+				t.Errorf("isHyperlink() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_isEmail(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{value: "anna@example.com", expected: true},
+		{value: "first.last+tag@mail.example.se", expected: true},
+		{value: "Anna <anna@example.com>", expected: false},
+		{value: "anna@localhost", expected: false},
+		{value: "anna.example.com", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := isEmail(tt.value); got != tt.expected {
+				t.Errorf("isEmail() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_CSV_SaveAsExcel_Hyperlinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xlsx")
+	c := New(
+		WithHeaders([]Column{{Name: "title", Type: StringType}, {Name: "url", Type: HyperlinkType}, {Name: "email", Type: EmailType}}),
+		WithRecords([][]Value{{"Home", "https://example.com", "anna@example.com"}, {"", "https://example.org", ""}}),
+	)
+	if err := c.SaveAsExcel(path, "", WithLinkText(map[string]string{"url": "title"})); err != nil {
+		t.Fatalf("SaveAsExcel() error = %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	defer f.Close()
+	tests := []struct {
+		cell  string
+		value string
+		link  string
+	}{
+		{cell: "B2", value: "Home", link: "https://example.com"},
+		{cell: "B3", value: "https://example.org", link: "https://example.org"},
+		{cell: "C2", value: "anna@example.com", link: "mailto:anna@example.com"},
+	}
+	for _, tt := range tests {
+		value, _ := f.GetCellValue("Sheet1", tt.cell)
+		ok, link, err := f.GetCellHyperLink("Sheet1", tt.cell)
+		if err != nil {
+			t.Fatalf("GetCellHyperLink() error = %v", err)
+		}
+		if value != tt.value || !ok || link != tt.link {
+			t.Errorf("SaveAsExcel() %s = %q linking to %q, expected %q linking to %q", tt.cell, value, link, tt.value, tt.link)
+		}
+	}
+	if ok, _, _ := f.GetCellHyperLink("Sheet1", "C3"); ok {
+		t.Errorf("SaveAsExcel() added a hyperlink to an empty cell")
+	}
+
+	if err := c.SaveAsExcel(path, "", WithLinkText(map[string]string{"title": "url"})); err == nil {
+		t.Errorf("SaveAsExcel() expected error for link text on a text column")
+	}
+}
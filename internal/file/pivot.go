@@ -0,0 +1,175 @@
+package file
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// pivotFunctions maps the supported pivot table functions to their Excel subtotal names.
+var pivotFunctions = map[string]string{
+	"sum":     "Sum",
+	"average": "Average",
+	"avg":     "Average",
+	"count":   "Count",
+	"min":     "Min",
+	"max":     "Max",
+}
+
+// Pivot describes a pivot table that is added on its own sheet, summarising the data written by SaveAsExcel.
+type Pivot struct {
+	// Sheet is the name of the sheet holding the pivot table, defaults to Pivot, Pivot2 and so on.
+	Sheet string
+	// Rows and Columns are the names of the columns whose values become the rows and columns of the pivot table.
+	Rows, Columns []string
+	// Filters are the names of the columns that can be used to filter the pivot table.
+	Filters []string
+	// Values are the summarised columns.
+	Values []PivotValue
+}
+
+// PivotValue is a column summarised in a pivot table.
+type PivotValue struct {
+	// Column is the name of the summarised column.
+	Column string
+	// Function is the summary function: sum, average, count, min or max.
+	Function string
+}
+
+// ParsePivot parses a pivot table specification such as "rows=region,cols=month,values=sum(amount)".
+// The keys are rows, cols, filters, values and sheet. Lists continue until the next key,
+// e.g. "rows=region,country". Values are written as function(column), or as column to sum it.
+func ParsePivot(spec string) (Pivot, error) {
+	keys, err := parseSpec(spec, "rows", "cols", "filters", "values", "sheet")
+	if err != nil {
+		return Pivot{}, err
+	}
+	p := Pivot{Rows: keys["rows"], Columns: keys["cols"], Filters: keys["filters"]}
+	if sheets := keys["sheet"]; len(sheets) > 0 {
+		p.Sheet = sheets[len(sheets)-1]
+	}
+	for _, value := range keys["values"] {
+		function, column := "sum", value
+		if open := strings.Index(value, "("); open >= 0 && strings.HasSuffix(value, ")") {
+			function, column = strings.ToLower(strings.TrimSpace(value[:open])), strings.TrimSpace(value[open+1:len(value)-1])
+		}
+		if _, ok := pivotFunctions[function]; !ok {
+			return Pivot{}, fmt.Errorf("unsupported pivot function %q, expected sum, average, count, min or max", function)
+		}
+		p.Values = append(p.Values, PivotValue{Column: column, Function: function})
+	}
+	if len(p.Values) == 0 {
+		return Pivot{}, fmt.Errorf("pivot table %q has no values", spec)
+	}
+	if len(p.Rows) == 0 && len(p.Columns) == 0 {
+		return Pivot{}, fmt.Errorf("pivot table %q has no rows or cols", spec)
+	}
+	return p, nil
+}
+
+// Check returns an error if the pivot table uses a column that is not among columns,
+// or uses a column as more than one of rows, cols and filters.
+func (p Pivot) Check(columns []string) error {
+	seen := make(map[string]bool)
+	for _, name := range slices.Concat(p.Rows, p.Columns, p.Filters) {
+		if !slices.Contains(columns, name) {
+			return fmt.Errorf("pivot table column %q not found", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("pivot table column %q is used more than once", name)
+		}
+		seen[name] = true
+	}
+	for _, value := range p.Values {
+		if !slices.Contains(columns, value.Column) {
+			return fmt.Errorf("pivot table column %q not found", value.Column)
+		}
+	}
+	return nil
+}
+
+// WithPivots adds a sheet with a pivot table for each of the pivots.
+func WithPivots(pivots ...Pivot) ExcelOption {
+	return func(o *excelOptions) {
+		o.pivots = append(o.pivots, pivots...)
+	}
+}
+
+// writePivots adds the pivot tables, each on its own sheet, using the header and records written to
+// the data sheet as their source. The pivot tables are refreshed by Excel when the workbook is opened.
+func (c *CSV) writePivots(f *excelize.File, sheetName string, pivots []Pivot, columns []string) error {
+	if len(pivots) == 0 {
+		return nil
+	}
+	if len(c.Records) == 0 {
+		return fmt.Errorf("cannot add a pivot table without any rows")
+	}
+	lastCell, err := excelize.CoordinatesToCellName(len(columns), len(c.Records)+1)
+	if err != nil {
+		return err
+	}
+	dataRange := fmt.Sprintf("%s!A1:%s", quoteSheetName(sheetName), lastCell)
+
+	for i, p := range pivots {
+		if err := p.Check(columns); err != nil {
+			return err
+		}
+		sheet := p.Sheet
+		if sheet == "" {
+			sheet = "Pivot"
+			if i > 0 {
+				sheet = fmt.Sprintf("Pivot%d", i+1)
+			}
+		}
+		if _, err := f.NewSheet(sheet); err != nil {
+			return err
+		}
+		// Excel lays out the pivot table when it is refreshed, so the range only needs to give its top left corner.
+		width := len(p.Rows) + max(len(p.Columns), 1)*len(p.Values)
+		lastPivotCell, err := excelize.CoordinatesToCellName(max(width, 2), len(c.Records)+3)
+		if err != nil {
+			return err
+		}
+		opts := &excelize.PivotTableOptions{
+			DataRange:       dataRange,
+			PivotTableRange: fmt.Sprintf("%s!A3:%s", quoteSheetName(sheet), lastPivotCell),
+			RowGrandTotals:  true,
+			ColGrandTotals:  true,
+			ShowDrill:       true,
+			ShowRowHeaders:  true,
+			ShowColHeaders:  true,
+			ShowLastColumn:  true,
+		}
+		for _, name := range p.Rows {
+			opts.Rows = append(opts.Rows, excelize.PivotTableField{Data: name, DefaultSubtotal: true})
+		}
+		for _, name := range p.Columns {
+			opts.Columns = append(opts.Columns, excelize.PivotTableField{Data: name, DefaultSubtotal: true})
+		}
+		for _, name := range p.Filters {
+			opts.Filter = append(opts.Filter, excelize.PivotTableField{Data: name})
+		}
+		for _, value := range p.Values {
+			subtotal := pivotFunctions[value.Function]
+			opts.Data = append(opts.Data, excelize.PivotTableField{
+				Data:     value.Column,
+				Name:     subtotal + " of " + value.Column,
+				Subtotal: subtotal,
+			})
+		}
+		if err := f.AddPivotTable(opts); err != nil {
+			return fmt.Errorf("failed to add pivot table on sheet %s: %w", sheet, err)
+		}
+	}
+	return nil
+}
+
+// quoteSheetName returns the sheet name quoted for use in a range reference, if needed.
+func quoteSheetName(name string) string {
+	if strings.ContainsAny(name, " -'!()") {
+		return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+	}
+	return name
+}
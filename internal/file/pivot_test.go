@@ -0,0 +1,123 @@
+package file
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func Test_parseSpec(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected map[string][]string
+		wantErr  bool
+	}{
+		{spec: "rows=region,country,values=sum(amount)", expected: map[string][]string{"rows": {"region", "country"}, "values": {"sum(amount)"}}},
+		{spec: "ROWS = region , cols=month", expected: map[string][]string{"rows": {"region"}, "cols": {"month"}}},
+		{spec: "values=round(amount,2)", expected: map[string][]string{"values": {"round(amount,2)"}}},
+		{spec: "region,cols=month", wantErr: true},
+		{spec: "rows=region,colour=red", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parseSpec(tt.spec, "rows", "cols", "values")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parseSpec() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_ParsePivot(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected Pivot
+		wantErr  bool
+	}{
+		{
+			spec: "rows=region,cols=month,values=sum(amount)",
+			expected: Pivot{
+				Rows: []string{"region"}, Columns: []string{"month"},
+				Values: []PivotValue{{Column: "amount", Function: "sum"}},
+			},
+		},
+		{
+			spec: "rows=region,country,filters=year,values=amount,AVG(qty),sheet=By Region",
+			expected: Pivot{
+				Sheet: "By Region", Rows: []string{"region", "country"}, Filters: []string{"year"},
+				Values: []PivotValue{{Column: "amount", Function: "sum"}, {Column: "qty", Function: "avg"}},
+			},
+		},
+		{spec: "rows=region", wantErr: true},
+		{spec: "values=sum(amount)", wantErr: true},
+		{spec: "rows=region,values=median(amount)", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParsePivot(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePivot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParsePivot() = %+v, expected %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_Pivot_Check(t *testing.T) {
+	columns := []string{"region", "month", "amount"}
+	tests := []struct {
+		name    string
+		pivot   Pivot
+		wantErr bool
+	}{
+		{name: "Valid", pivot: Pivot{Rows: []string{"region"}, Columns: []string{"month"}, Values: []PivotValue{{Column: "amount", Function: "sum"}}}},
+		{name: "Unknown row", pivot: Pivot{Rows: []string{"country"}, Values: []PivotValue{{Column: "amount", Function: "sum"}}}, wantErr: true},
+		{name: "Unknown value", pivot: Pivot{Rows: []string{"region"}, Values: []PivotValue{{Column: "qty", Function: "sum"}}}, wantErr: true},
+		{name: "Row and column", pivot: Pivot{Rows: []string{"region"}, Columns: []string{"region"}, Values: []PivotValue{{Column: "amount", Function: "sum"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.pivot.Check(columns); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_CSV_SaveAsExcel_Pivot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xlsx")
+	c := New(
+		WithHeaders([]Column{{Name: "region", Type: StringType}, {Name: "month", Type: StringType}, {Name: "amount", Type: FloatType}}),
+		WithRecords([][]Value{{"north", "Jan", 10.0}, {"south", "Jan", 20.0}, {"north", "Feb", 30.0}}),
+	)
+	pivot := Pivot{Rows: []string{"region"}, Columns: []string{"month"}, Values: []PivotValue{{Column: "amount", Function: "sum"}}}
+	if err := c.SaveAsExcel(path, "", WithPivots(pivot)); err != nil {
+		t.Fatalf("SaveAsExcel() error = %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	defer f.Close()
+	tables, err := f.GetPivotTables("Pivot")
+	if err != nil {
+		t.Fatalf("GetPivotTables() error = %v", err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("SaveAsExcel() added %d pivot tables, expected 1", len(tables))
+	}
+	if tables[0].DataRange != "Sheet1!A1:C4" {
+		t.Errorf("SaveAsExcel() pivot data range = %s, expected Sheet1!A1:C4", tables[0].DataRange)
+	}
+	if len(tables[0].Data) != 1 || tables[0].Data[0].Subtotal != "Sum" {
+		t.Errorf("SaveAsExcel() pivot data = %+v, expected sum of amount", tables[0].Data)
+	}
+}
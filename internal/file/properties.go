@@ -0,0 +1,65 @@
+package file
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// application is the name recorded as the application, and default author, of the workbooks written by SaveAsExcel.
+const application = "csv2excel"
+
+// Properties are the document properties of a workbook, shown by Excel and document management systems.
+type Properties struct {
+	Title       string
+	Subject     string
+	Author      string
+	Company     string
+	Keywords    string
+	Description string
+	// Created is when the workbook was created. The modification time is always the time it is written.
+	Created time.Time
+}
+
+// WithProperties sets the document properties of the workbook. Properties that are not given
+// default to those described in SaveAsExcel.
+func WithProperties(p Properties) ExcelOption {
+	return func(o *excelOptions) {
+		o.properties = p
+	}
+}
+
+// writeProperties sets the document properties of the workbook. The title defaults to the name
+// of the source file, or of the Excel file if there is no source file, and the creation time to now.
+// The modification time is always now, as the workbook is written now.
+func (c *CSV) writeProperties(f *excelize.File, filePath string, p Properties) error {
+	if p.Title == "" {
+		p.Title = filepath.Base(c.FilePath)
+		if c.FilePath == "" {
+			p.Title = strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+		}
+	}
+	if p.Author == "" {
+		p.Author = application
+	}
+	now := time.Now()
+	if p.Created.IsZero() {
+		p.Created = now
+	}
+	err := f.SetDocProps(&excelize.DocProperties{
+		Title:          p.Title,
+		Subject:        p.Subject,
+		Creator:        p.Author,
+		LastModifiedBy: p.Author,
+		Keywords:       p.Keywords,
+		Description:    p.Description,
+		Created:        p.Created.UTC().Format(time.RFC3339),
+		Modified:       now.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	return f.SetAppProps(&excelize.AppProperties{Application: application, Company: p.Company})
+}
@@ -0,0 +1,84 @@
+package file
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func Test_CSV_SaveAsExcel_Properties(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		filePath   string
+		properties Properties
+		expected   excelize.DocProperties
+		company    string
+	}{
+		{
+			name:     "Defaults",
+			filePath: filepath.Join("data", "sales.csv"),
+			expected: excelize.DocProperties{Title: "sales.csv", Creator: application},
+		},
+		{
+			name:     "Defaults without source file",
+			expected: excelize.DocProperties{Title: "report", Creator: application},
+		},
+		{
+			name:     "Given properties",
+			filePath: "sales.csv",
+			properties: Properties{
+				Title: "Sales", Subject: "Monthly sales", Author: "Finance", Company: "Example AB",
+				Keywords: "sales,2024", Description: "Sales per region", Created: created,
+			},
+			expected: excelize.DocProperties{
+				Title: "Sales", Subject: "Monthly sales", Creator: "Finance", Keywords: "sales,2024",
+				Description: "Sales per region", Created: "2024-05-01T12:30:00Z",
+			},
+			company: "Example AB",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "report.xlsx")
+			c := New(WithFilePath(tt.filePath), WithHeaders([]Column{{Name: "id", Type: StringType}}))
+			before := time.Now().UTC().Truncate(time.Second)
+			if err := c.SaveAsExcel(path, "", WithProperties(tt.properties)); err != nil {
+				t.Fatalf("SaveAsExcel() error = %v", err)
+			}
+
+			f, err := excelize.OpenFile(path)
+			if err != nil {
+				t.Fatalf("OpenFile() error = %v", err)
+			}
+			defer f.Close()
+			props, err := f.GetDocProps()
+			if err != nil {
+				t.Fatalf("GetDocProps() error = %v", err)
+			}
+			if props.Title != tt.expected.Title || props.Subject != tt.expected.Subject || props.Creator != tt.expected.Creator ||
+				props.Keywords != tt.expected.Keywords || props.Description != tt.expected.Description {
+				t.Errorf("SaveAsExcel() properties = %+v, expected %+v", props, tt.expected)
+			}
+			if tt.expected.Created != "" {
+				if props.Created != tt.expected.Created {
+					t.Errorf("SaveAsExcel() created = %s, expected %s", props.Created, tt.expected.Created)
+				}
+			} else if parsed, err := time.Parse(time.RFC3339, props.Created); err != nil || parsed.Before(before) {
+				t.Errorf("SaveAsExcel() created = %s, expected the conversion time", props.Created)
+			}
+			if parsed, err := time.Parse(time.RFC3339, props.Modified); err != nil || parsed.Before(before) {
+				t.Errorf("SaveAsExcel() modified = %s, expected the conversion time", props.Modified)
+			}
+			app, err := f.GetAppProps()
+			if err != nil {
+				t.Fatalf("GetAppProps() error = %v", err)
+			}
+			if app.Company != tt.company || app.Application != application {
+				t.Errorf("SaveAsExcel() app properties = %+v, expected company %q", app, tt.company)
+			}
+		})
+	}
+}
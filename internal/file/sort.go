@@ -0,0 +1,118 @@
+package file
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortKey describes a column to sort the records by.
+type SortKey struct {
+	// Column is a column selector, see SelectColumns.
+	Column string
+	// Descending reverses the sort order of the column.
+	Descending bool
+}
+
+// ParseSortKeys parses sort keys on the form "col" or "+col" for ascending
+// and "-col" for descending order.
+func ParseSortKeys(specs []string) ([]SortKey, error) {
+	keys := make([]SortKey, 0, len(specs))
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		key := SortKey{Column: spec}
+		if strings.HasPrefix(spec, "-") {
+			key = SortKey{Column: spec[1:], Descending: true}
+		} else if strings.HasPrefix(spec, "+") {
+			key = SortKey{Column: spec[1:]}
+		}
+		if key.Column == "" {
+			return nil, fmt.Errorf("invalid sort key %q", spec)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Sort sorts the records by the given keys, using the type of each column to decide whether
+// values are compared numerically, chronologically or lexically. The sort is stable, so records
+// with equal keys keep their original order. Empty values are placed first if nullsFirst is true
+// and last otherwise, regardless of the sort direction.
+func (c *CSV) Sort(keys []SortKey, nullsFirst bool) error {
+	indexes := make([]int, len(keys))
+	for i, key := range keys {
+		index, err := c.ColumnIndex(key.Column)
+		if err != nil {
+			return err
+		}
+		indexes[i] = index
+	}
+
+	sort.SliceStable(c.Records, func(i, j int) bool {
+		for k, key := range keys {
+			a, b := valueAt(c.Records[i], indexes[k]), valueAt(c.Records[j], indexes[k])
+			aNull, bNull := isNull(a), isNull(b)
+			if aNull || bNull {
+				if aNull == bNull {
+					continue
+				}
+				return aNull == nullsFirst
+			}
+			result := compareTyped(a, b, c.Headers[indexes[k]].Type)
+			if result == 0 {
+				continue
+			}
+			if key.Descending {
+				return result > 0
+			}
+			return result < 0
+		}
+		return false
+	})
+	return nil
+}
+
+// compareTyped compares two values according to the column type and returns -1, 0 or 1.
+// Values that cannot be interpreted as the column type are compared with compareValues.
+func compareTyped(a, b Value, columnType ColumnType) int {
+	switch columnType {
+	case IntegerType, FloatType:
+		if x, ok := toFloat(a); ok {
+			if y, ok := toFloat(b); ok {
+				switch {
+				case x < y:
+					return -1
+				case x > y:
+					return 1
+				}
+				return 0
+			}
+		}
+	case DateType:
+		if x, ok := toTime(a); ok {
+			if y, ok := toTime(b); ok {
+				return x.Compare(y)
+			}
+		}
+	case StringType:
+		return strings.Compare(FormatValue(a), FormatValue(b))
+	}
+	return compareValues(a, b)
+}
+
+// valueAt returns the value at the index of the record, or nil if the record is too short.
+func valueAt(record []Value, index int) Value {
+	if index < len(record) {
+		return record[index]
+	}
+	return nil
+}
+
+// isNull reports whether the value is missing or empty.
+func isNull(v Value) bool {
+	if v == nil {
+		return true
+	}
+	s, ok := v.(string)
+	return ok && strings.TrimSpace(s) == ""
+}
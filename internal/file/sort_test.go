@@ -0,0 +1,111 @@
+package file
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_ParseSortKeys(t *testing.T) {
+	got, err := ParseSortKeys([]string{"name", "-amount", "+date"})
+	if err != nil {
+		t.Fatalf("ParseSortKeys() error = %v", err)
+	}
+	expected := []SortKey{
+		{Column: "name"},
+		{Column: "amount", Descending: true},
+		{Column: "date"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ParseSortKeys() = %v, expected %v", got, expected)
+	}
+	if _, err := ParseSortKeys([]string{"-"}); err == nil {
+		t.Errorf("ParseSortKeys() expected error for empty column")
+	}
+}
+
+func Test_CSV_Sort(t *testing.T) {
+	newCSV := func() *CSV {
+		return &CSV{
+			Headers: []Column{
+				{Name: "id", Type: StringType},
+				{Name: "group", Type: StringType},
+				{Name: "amount", Type: IntegerType},
+			},
+			Records: [][]Value{
+				{"1", "b", "10"},
+				{"2", "a", "9"},
+				{"3", "b", ""},
+				{"4", "a", "100"},
+				{"5", "b", "10"},
+			},
+		}
+	}
+	tests := []struct {
+		name       string
+		keys       []SortKey
+		nullsFirst bool
+		expected   []Value
+		wantErr    bool
+	}{
+		{
+			name:     "Numeric ascending with nulls last",
+			keys:     []SortKey{{Column: "amount"}},
+			expected: []Value{"2", "1", "5", "4", "3"},
+		},
+		{
+			name:       "Numeric descending with nulls first",
+			keys:       []SortKey{{Column: "amount", Descending: true}},
+			nullsFirst: true,
+			expected:   []Value{"3", "4", "1", "5", "2"},
+		},
+		{
+			name:     "Multiple keys",
+			keys:     []SortKey{{Column: "group"}, {Column: "amount", Descending: true}},
+			expected: []Value{"4", "2", "1", "5", "3"},
+		},
+		{
+			name:    "Unknown column",
+			keys:    []SortKey{{Column: "missing"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCSV()
+			err := c.Sort(tt.keys, tt.nullsFirst)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Sort() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			got := make([]Value, len(c.Records))
+			for i, record := range c.Records {
+				got[i] = record[0]
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Sort() ids = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_CSV_Sort_InferredNullableColumn(t *testing.T) {
+	c := &CSV{
+		Headers: []Column{{Name: "id", Type: StringType}, {Name: "amount", Type: StringType}},
+		Records: [][]Value{{"1", "10"}, {"2", ""}, {"3", "5"}, {"4", "100"}},
+	}
+	c.InferColumnTypes()
+	if err := c.Sort([]SortKey{{Column: "amount"}}, false); err != nil {
+		t.Fatalf("Sort() error = %v", err)
+	}
+	got := make([]Value, len(c.Records))
+	for i, record := range c.Records {
+		got[i] = record[1]
+	}
+	if expected := []Value{"5", "10", "100", ""}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Sort() amounts = %v, expected %v", got, expected)
+	}
+}
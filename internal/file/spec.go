@@ -0,0 +1,52 @@
+package file
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// parseSpec parses a specification of the form "key=value,key=value1,value2" into the values of
+// each key. Values without a key belong to the key before them, and commas inside parentheses do not
+// separate values, e.g. "rows=region,country,values=sum(amount)". Returns an error for keys
+// that are not in allowed.
+func parseSpec(spec string, allowed ...string) (map[string][]string, error) {
+	values := make(map[string][]string)
+	key := ""
+	for _, item := range splitTopLevel(spec) {
+		item = strings.TrimSpace(item)
+		if k, v, ok := strings.Cut(item, "="); ok && !strings.Contains(k, "(") {
+			key = strings.ToLower(strings.TrimSpace(k))
+			if !slices.Contains(allowed, key) {
+				return nil, fmt.Errorf("unknown key %q in %q, expected %s", key, spec, strings.Join(allowed, ", "))
+			}
+			item = strings.TrimSpace(v)
+		} else if key == "" {
+			return nil, fmt.Errorf("expected key=value but found %q in %q", item, spec)
+		}
+		if item != "" {
+			values[key] = append(values[key], item)
+		}
+	}
+	return values, nil
+}
+
+// splitTopLevel splits s on the commas that are not inside parentheses.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,166 @@
+package file
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// SummarySheet is the name of the sheet added by WithSummary.
+const SummarySheet = "Summary"
+
+// totalFunctions maps the supported totals functions to their label in the totals row.
+var totalFunctions = map[string]string{
+	"SUM":     "Sum",
+	"AVERAGE": "Average",
+	"COUNT":   "Count",
+}
+
+// WithTotals appends a row under the data for each of the functions, calculating the function
+// over every numeric column as an Excel formula. The supported functions are sum, average and count.
+func WithTotals(functions ...string) ExcelOption {
+	return func(o *excelOptions) {
+		for _, function := range functions {
+			o.totals = append(o.totals, strings.ToUpper(function))
+		}
+	}
+}
+
+// WithSummary adds a sheet with statistics for each column, see Summarize.
+func WithSummary() ExcelOption {
+	return func(o *excelOptions) {
+		o.summary = true
+	}
+}
+
+// ColumnSummary holds statistics for a column of a CSV.
+type ColumnSummary struct {
+	Column
+	// Count is the number of values that are not empty.
+	Count int
+	// Nulls is the number of empty values.
+	Nulls int
+	// Distinct is the number of different values that are not empty.
+	Distinct int
+	// Min and Max are the smallest and largest values of numeric and date columns, or nil.
+	Min, Max Value
+	// Mean is the average of numeric columns, or nil.
+	Mean Value
+}
+
+// Summarize returns statistics for each column, computed from the records. Minimum, maximum and
+// mean are only computed for columns of a numeric or date type, so the column types should have
+// been converted first.
+func (c *CSV) Summarize() []ColumnSummary {
+	summaries := make([]ColumnSummary, len(c.Headers))
+	for i, column := range c.Headers {
+		s := ColumnSummary{Column: column}
+		distinct := make(map[string]bool)
+		sum := 0.0
+		for _, record := range c.Records {
+			value := valueAt(record, i)
+			if isNull(value) {
+				s.Nulls++
+				continue
+			}
+			s.Count++
+			distinct[FormatValue(value)] = true
+			if column.Type != IntegerType && column.Type != FloatType && column.Type != DateType {
+				continue
+			}
+			if s.Min == nil || compareTyped(value, s.Min, column.Type) < 0 {
+				s.Min = value
+			}
+			if s.Max == nil || compareTyped(value, s.Max, column.Type) > 0 {
+				s.Max = value
+			}
+			if number, ok := toFloat(value); ok {
+				sum += number
+			}
+		}
+		s.Distinct = len(distinct)
+		if (column.Type == IntegerType || column.Type == FloatType) && s.Count > 0 {
+			s.Mean = sum / float64(s.Count)
+		}
+		summaries[i] = s
+	}
+	return summaries
+}
+
+// writeTotals writes a totals row for each function under the given number of data rows on the sheet.
+// Numeric columns and the formula columns after the CSV columns are totalled. The label of the row is
+// written in the first column without a total, or in a column after the last one if every column has one.
+func (c *CSV) writeTotals(f *excelize.File, sheetName string, functions []string, width, rows int) error {
+	if len(functions) == 0 || rows == 0 {
+		return nil
+	}
+	totalled := make([]bool, width)
+	labelColumn := width
+	for j := width - 1; j >= 0; j-- {
+		totalled[j] = j >= len(c.Headers) || c.Headers[j].Type == IntegerType || c.Headers[j].Type == FloatType
+		if !totalled[j] {
+			labelColumn = j
+		}
+	}
+	style, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	lastRow := rows + 1
+	for i, function := range functions {
+		label, ok := totalFunctions[function]
+		if !ok {
+			return fmt.Errorf("unsupported totals function %q, expected sum, average or count", strings.ToLower(function))
+		}
+		row := lastRow + i + 1
+		labelCell, err := excelize.CoordinatesToCellName(labelColumn+1, row)
+		if err != nil {
+			return err
+		}
+		if err := f.SetCellValue(sheetName, labelCell, label); err != nil {
+			return err
+		}
+		for j := range totalled {
+			if !totalled[j] {
+				continue
+			}
+			cell, err := excelize.CoordinatesToCellName(j+1, row)
+			if err != nil {
+				return err
+			}
+			letters, err := excelize.ColumnNumberToName(j + 1)
+			if err != nil {
+				return err
+			}
+			formula := fmt.Sprintf("%s(%s2:%s%d)", function, letters, letters, lastRow)
+			if err := f.SetCellFormula(sheetName, cell, formula); err != nil {
+				return err
+			}
+		}
+		first, _ := excelize.CoordinatesToCellName(1, row)
+		last, _ := excelize.CoordinatesToCellName(max(width, labelColumn+1), row)
+		if err := f.SetCellStyle(sheetName, first, last, style); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSummary adds the summary sheet with a row of statistics for each column.
+func (c *CSV) writeSummary(f *excelize.File) error {
+	if _, err := f.NewSheet(SummarySheet); err != nil {
+		return err
+	}
+	header := []interface{}{"Column", "Type", "Count", "Nulls", "Distinct", "Min", "Max", "Mean"}
+	if err := f.SetSheetRow(SummarySheet, "A1", &header); err != nil {
+		return err
+	}
+	for i, s := range c.Summarize() {
+		row := []interface{}{s.Name, s.Type.String(), s.Count, s.Nulls, s.Distinct, s.Min, s.Max, s.Mean}
+		if err := f.SetSheetRow(SummarySheet, fmt.Sprintf("A%d", i+2), &row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package file
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func Test_CSV_Summarize(t *testing.T) {
+	c := &CSV{
+		Headers: []Column{
+			{Name: "country", Type: StringType},
+			{Name: "amount", Type: FloatType},
+			{Name: "date", Type: DateType},
+		},
+		Records: [][]Value{
+			{"SE", 10.0, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+			{"NO", 30.0, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{"SE", "", nil},
+			{"", 20.0, time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	expected := []ColumnSummary{
+		{Column: c.Headers[0], Count: 3, Nulls: 1, Distinct: 2},
+		{Column: c.Headers[1], Count: 3, Nulls: 1, Distinct: 3, Min: 10.0, Max: 30.0, Mean: 20.0},
+		{
+			Column: c.Headers[2], Count: 3, Nulls: 1, Distinct: 3,
+			Min: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Max: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	if got := c.Summarize(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Summarize() = %+v, expected %+v", got, expected)
+	}
+}
+
+func Test_CSV_Summarize_NullableColumn(t *testing.T) {
+	c := &CSV{
+		Headers: []Column{{Name: "amount", Type: StringType}},
+		Records: [][]Value{{"10"}, {""}, {"5"}, {"100"}},
+	}
+	c.InferColumnTypes()
+	c.ConvertColumnTypes()
+	expected := []ColumnSummary{
+		{Column: Column{Name: "amount", Type: IntegerType}, Count: 3, Nulls: 1, Distinct: 3, Min: int64(5), Max: int64(100), Mean: 115.0 / 3},
+	}
+	if got := c.Summarize(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Summarize() = %+v, expected %+v", got, expected)
+	}
+}
+
+func Test_CSV_SaveAsExcel_TotalsPlacement(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  []Column
+		records  [][]Value
+		formulas []Formula
+		cells    map[string]string
+		label    string
+	}{
+		{
+			name:    "Label in first column without a total",
+			headers: []Column{{Name: "qty", Type: IntegerType}, {Name: "region", Type: StringType}},
+			records: [][]Value{{int64(2), "north"}, {"", "south"}},
+			cells:   map[string]string{"A4": "SUM(A2:A3)"},
+			label:   "B4",
+		},
+		{
+			name:     "Label after formula columns",
+			headers:  []Column{{Name: "qty", Type: IntegerType}, {Name: "price", Type: FloatType}},
+			records:  [][]Value{{int64(2), 1.5}, {int64(3), 2.5}},
+			formulas: []Formula{{Name: "total", Expression: "[qty]*[price]"}},
+			cells:    map[string]string{"A4": "SUM(A2:A3)", "B4": "SUM(B2:B3)", "C4": "SUM(C2:C3)"},
+			label:    "D4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.xlsx")
+			c := New(WithHeaders(tt.headers), WithRecords(tt.records))
+			if err := c.SaveAsExcel(path, "", WithTotals("sum"), WithFormulas(tt.formulas...)); err != nil {
+				t.Fatalf("SaveAsExcel() error = %v", err)
+			}
+
+			f, err := excelize.OpenFile(path)
+			if err != nil {
+				t.Fatalf("OpenFile() error = %v", err)
+			}
+			defer f.Close()
+			for cell, expected := range tt.cells {
+				if formula, _ := f.GetCellFormula("Sheet1", cell); formula != expected {
+					t.Errorf("SaveAsExcel() formula in %s = %q, expected %q", cell, formula, expected)
+				}
+			}
+			if label, _ := f.GetCellValue("Sheet1", tt.label); label != "Sum" {
+				t.Errorf("SaveAsExcel() label in %s = %q, expected %q", tt.label, label, "Sum")
+			}
+		})
+	}
+}
+
+func Test_CSV_SaveAsExcel_TotalsAndSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xlsx")
+	c := New(
+		WithHeaders([]Column{{Name: "region", Type: StringType}, {Name: "qty", Type: IntegerType}, {Name: "price", Type: FloatType}}),
+		WithRecords([][]Value{{"north", int64(2), 1.5}, {"south", int64(3), 2.5}}),
+	)
+	if err := c.SaveAsExcel(path, "", WithTotals("sum", "average"), WithSummary()); err != nil {
+		t.Fatalf("SaveAsExcel() error = %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	defer f.Close()
+	cells := map[string]string{"B4": "SUM(B2:B3)", "C4": "SUM(C2:C3)", "B5": "AVERAGE(B2:B3)", "C5": "AVERAGE(C2:C3)"}
+	for cell, expected := range cells {
+		if formula, _ := f.GetCellFormula("Sheet1", cell); formula != expected {
+			t.Errorf("SaveAsExcel() formula in %s = %q, expected %q", cell, formula, expected)
+		}
+	}
+	for cell, expected := range map[string]string{"A4": "Sum", "A5": "Average"} {
+		if label, _ := f.GetCellValue("Sheet1", cell); label != expected {
+			t.Errorf("SaveAsExcel() label in %s = %q, expected %q", cell, label, expected)
+		}
+	}
+
+	rows, err := f.GetRows(SummarySheet)
+	if err != nil {
+		t.Fatalf("GetRows() error = %v", err)
+	}
+	expected := [][]string{
+		{"Column", "Type", "Count", "Nulls", "Distinct", "Min", "Max", "Mean"},
+		{"region", "string", "2", "0", "2"},
+		{"qty", "integer", "2", "0", "2", "2", "3", "2.5"},
+		{"price", "float", "2", "0", "2", "1.5", "2.5", "2"},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("SaveAsExcel() summary = %v, expected %v", rows, expected)
+	}
+
+	if err := c.SaveAsExcel(path, "", WithTotals("median")); err == nil {
+		t.Errorf("SaveAsExcel() expected error for unsupported totals function")
+	}
+}